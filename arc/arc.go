@@ -2,6 +2,7 @@ package arc
 
 import (
 	"archiver/arc/internal/generic"
+	"archiver/arc/internal/header"
 	c "archiver/compressor"
 	"archiver/errtype"
 	"archiver/filesystem"
@@ -14,13 +15,26 @@ import (
 )
 
 const (
-	magicNumber  uint16 = 0x5717
-	arcHeaderLen int64  = 3
+	magicNumber uint16 = 0x5717
+	// Признак версионированного заголовка в байте после
+	// сигнатуры. В заголовке версии 0 в этом байте
+	// записан тип компрессора.
+	versionMark   byte = 0x80
+	formatVersion byte = 1 // Текущая версия формата
+
+	arcHeaderLen    int64 = 8 // Длина заголовка текущей версии без словаря
+	legacyHeaderLen int64 = 3 // Длина заголовка версии 0
 )
 
 // Структура параметров архива
 type Arc struct {
-	arcPath string // Путь к файлу архива
+	arcPath   string       // Путь к файлу архива
+	headerLen int64        // Длина заголовка архива
+	flags     header.Flags // Флаги возможностей формата
+	update    bool         // Архив дополняется
+	appendAt  int64        // Смещение конца записей дополняемого архива
+	tail      []byte       // Индекс дополняемого архива до дополнения
+	edit      bool         // Архив пишется во временный файл и заменяется им
 	generic.RestoreParams
 }
 
@@ -39,6 +53,39 @@ func NewArc(p params.Params) (arc *Arc, err error) {
 	if len(p.InputPaths) > 0 {
 		arc.Ct = p.Ct
 		arc.Cl = p.Cl
+		arc.AutoStore = p.AutoStore
+		arc.Lzw = p.Lzw
+		arc.Solid = p.Solid
+		arc.Dedup = p.Dedup
+		arc.headerLen = arcHeaderLen
+		arc.flags = header.SupportedFlags
+
+		if p.DictPath == "auto" {
+			arc.AutoDict = true
+		} else if p.DictPath != "" {
+			if arc.Dict, err = os.ReadFile(p.DictPath); err != nil {
+				return nil, errtype.Join(ErrReadDict, err)
+			}
+			// Компрессоры используют только конец словаря
+			arc.Dict = arc.Dict[max(0, len(arc.Dict)-c.MaxDictSize):]
+		}
+
+		if arc.Lzw == (c.LzwParams{}) { // Параметры не заданы
+			arc.Lzw = c.DefaultLzwParams
+		}
+
+		if _, err = os.Stat(arc.arcPath); p.Update && err == nil {
+			if err = arc.openUpdate(p); err != nil {
+				return nil, err
+			}
+		}
+	} else if p.Transcode {
+		arc.edit = true
+		arc.Ct = p.Ct
+		arc.Cl = p.Cl
+		arc.AutoStore = p.AutoStore
+	} else if p.Merge { // Параметры нового архива берутся при объединении
+		arc.edit = true
 	} else {
 		arcFile, err := os.Open(arc.arcPath)
 		if err != nil {
@@ -46,35 +93,31 @@ func NewArc(p params.Params) (arc *Arc, err error) {
 		}
 		defer arcFile.Close()
 
-		var magic uint16
-		if err = filesystem.BinaryRead(arcFile, &magic); err != nil {
-			return nil, errtype.Join(ErrReadMagic, err)
-		}
-		if magic != magicNumber {
-			return nil, ErrNotArc(arcFile.Name())
-		}
-
-		var compType byte
-		if err = filesystem.BinaryRead(arcFile, &compType); err != nil {
+		if err = arc.readArcHeader(arcFile, arcFile.Name()); err != nil {
 			return nil, err
 		}
 
-		if compType <= byte(c.ZLib) {
-			arc.Ct = c.Type(compType)
-		} else {
-			return nil, ErrUnknownComp
-		}
-
+		arc.edit = p.Remove || p.Rename
 		arc.Integ = p.XIntegTest
+		arc.RestoreOwner = p.RestoreOwner
+		arc.RestoreXattrs = p.RestoreXattrs
 		arc.OutputDir = p.OutputDir
 	}
 
 	return arc, nil
 }
 
-// Удаляет архив
+// Удаляет архив. Дополняемому архиву возвращается
+// прежнее содержимое, у изменяемого архива удаляется
+// временный файл.
 func (arc Arc) RemoveTmp() {
-	os.Remove(arc.arcPath)
+	if arc.edit {
+		os.Remove(arc.tmpPath())
+	} else if !arc.update {
+		os.Remove(arc.arcPath)
+	} else if arcFile, err := os.OpenFile(arc.arcPath, os.O_RDWR, 0644); err == nil {
+		arc.rollback(arcFile)
+	}
 }
 
 // Закрывает файл архива и удаляет его
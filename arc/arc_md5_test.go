@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"slices"
@@ -37,6 +38,7 @@ func checkMD5(t *testing.T, path string) bool {
 			}()
 
 			checkFileMD5(t, files[i])
+			checkFileAttrs(t, files[i])
 		}(i)
 	}
 
@@ -68,6 +70,75 @@ func checkFileMD5(t *testing.T, inFilepath string) {
 	}
 }
 
+func checkFileAttrs(t *testing.T, inFilepath string) {
+	outFilepath := filepath.Join(outPath, filesystem.Clean(inFilepath))
+
+	inInfo, err := os.Stat(inFilepath)
+	if err != nil {
+		t.Fatal("inMode:", err)
+	}
+
+	outInfo, err := os.Stat(outFilepath)
+	if err != nil {
+		t.Fatal("outMode:", err)
+	}
+
+	if inInfo.Mode() != outInfo.Mode() {
+		t.Errorf(
+			"Mismatched mode '%s':\nexpected %s got %s",
+			inFilepath, inInfo.Mode(), outInfo.Mode(),
+		)
+		t.FailNow()
+	}
+
+	if !inInfo.ModTime().Equal(outInfo.ModTime()) {
+		t.Errorf(
+			"Mismatched mtime '%s':\nexpected %s got %s",
+			inFilepath, inInfo.ModTime(), outInfo.ModTime(),
+		)
+		t.FailNow()
+	}
+}
+
+func checkDirs(t *testing.T, path string) bool {
+	err := filepath.WalkDir(path, func(inDirpath string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return err
+		}
+
+		inInfo, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		outDirpath := filepath.Join(outPath, filesystem.Clean(inDirpath))
+		outInfo, err := os.Stat(outDirpath)
+		if err != nil {
+			return err
+		}
+
+		if inInfo.Mode() != outInfo.Mode() {
+			t.Errorf(
+				"Mismatched mode '%s':\nexpected %s got %s",
+				inDirpath, inInfo.Mode(), outInfo.Mode(),
+			)
+		}
+
+		if !inInfo.ModTime().Equal(outInfo.ModTime()) {
+			t.Errorf(
+				"Mismatched mtime '%s':\nexpected %s got %s",
+				inDirpath, inInfo.ModTime(), outInfo.ModTime(),
+			)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal("error during check dirs:", err)
+	}
+
+	return !t.Failed()
+}
+
 func hashFileMD5(filePath string) (MD5hash, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
@@ -4,16 +4,20 @@ import (
 	"archiver/arc"
 	"archiver/compressor"
 	p "archiver/params"
+	"bytes"
 	"crypto/md5"
 	"fmt"
 	"io"
 	"io/fs"
 	"log"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
 	"slices"
+	"strings"
 	"testing"
+	"time"
 )
 
 const (
@@ -53,6 +57,637 @@ func TestZlibAll(t *testing.T) {
 	runTestAll(t, compressor.ZLib)
 }
 
+func TestFlateAll(t *testing.T) {
+	runTestAll(t, compressor.Flate)
+}
+
+func TestLz4All(t *testing.T) {
+	runTestAll(t, compressor.LZ4)
+}
+
+func TestAutoAll(t *testing.T) {
+	params.AutoStore = true
+	t.Cleanup(func() { params.AutoStore = false })
+	runTestAll(t, compressor.GZip)
+}
+
+func TestZlibDictAll(t *testing.T) {
+	params.DictPath = "auto"
+	t.Cleanup(func() { params.DictPath = "" })
+	runTestAll(t, compressor.ZLib)
+}
+
+func TestFlateDictAll(t *testing.T) {
+	params.DictPath = "auto"
+	t.Cleanup(func() { params.DictPath = "" })
+	runTestAll(t, compressor.Flate)
+}
+
+func TestGzipSolidAll(t *testing.T) {
+	params.Solid = true
+	t.Cleanup(func() { params.Solid = false })
+	runTestAll(t, compressor.GZip)
+}
+
+func TestLz4SolidByEntry(t *testing.T) {
+	params.Solid = true
+	t.Cleanup(func() { params.Solid = false })
+	runTestByEntry(t, compressor.LZ4)
+}
+
+func TestGzipDedupAll(t *testing.T) {
+	params.Dedup = true
+	t.Cleanup(func() { params.Dedup = false })
+	runTestAll(t, compressor.GZip)
+}
+
+func TestGzipUpdate(t *testing.T) {
+	t.Cleanup(clearArcOut)
+	initRootEnts(t)
+	params.Ct = compressor.GZip
+
+	// Архив с первым элементом дополняется остальными
+	params.InputPaths = []string{filepath.Join(prefix, testPath, rootEnts[0].Name())}
+	archive, err := arc.NewArc(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	disableStdout()
+	if err = archive.Compress(params.InputPaths); err != nil {
+		enableStdout()
+		t.Fatal(err)
+	}
+	enableStdout()
+
+	params.Update = true
+	t.Cleanup(func() { params.Update = false })
+	runAll(t)
+}
+
+func TestGzipRemove(t *testing.T) {
+	t.Cleanup(clearArcOut)
+	initRootEnts(t)
+	params.Ct = compressor.GZip
+
+	params.InputPaths = nil
+	for _, rootEnt := range rootEnts {
+		path := filepath.Join(prefix, testPath, rootEnt.Name())
+		params.InputPaths = append(params.InputPaths, path)
+	}
+
+	archive, err := arc.NewArc(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	disableStdout()
+	if err = archive.Compress(params.InputPaths); err != nil {
+		enableStdout()
+		t.Fatal(err)
+	}
+	enableStdout()
+
+	// Из архива удаляется первая директория
+	removed := filepath.Join(testPath, rootEnts[0].Name())
+	paramsCopy := params
+	paramsCopy.InputPaths = nil
+	paramsCopy.Remove = true
+	paramsCopy.Patterns = []string{filepath.ToSlash(removed)}
+
+	if archive, err = arc.NewArc(paramsCopy); err != nil {
+		t.Fatal(err)
+	}
+
+	disableStdout()
+	err = archive.Remove(paramsCopy.Patterns)
+	if err == nil {
+		paramsCopy.Remove = false
+		if archive, err = arc.NewArc(paramsCopy); err == nil {
+			err = archive.Decompress(nil)
+		}
+	}
+	enableStdout()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = os.Stat(filepath.Join(outPath, removed)); err == nil {
+		t.Errorf("Removed '%s' was decompressed", removed)
+	}
+
+	for _, rootEnt := range rootEnts[1:] {
+		checkMD5(t, filepath.Join(prefix, testPath, rootEnt.Name()))
+	}
+}
+
+func TestGzipSolidRemove(t *testing.T) {
+	t.Cleanup(clearArcOut)
+
+	var (
+		dir   = t.TempDir()
+		files = map[string][]byte{}
+	)
+	for _, name := range []string{"a", "b", "c", "d"} {
+		files[name] = bytes.Repeat([]byte("solid "+name+" "), 100)
+		if err := os.WriteFile(filepath.Join(dir, name), files[name], 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	saved := params
+	t.Cleanup(func() { params = saved })
+	params.Ct = compressor.GZip
+	params.Solid = true
+	params.InputPaths = []string{dir}
+
+	archive, err := arc.NewArc(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	disableStdout()
+	err = archive.Compress(params.InputPaths)
+	enableStdout()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Удаляются первый и средний файлы группы, затем
+	// все файлы, кроме одного
+	inArc := strings.TrimPrefix(filepath.ToSlash(dir), "/")
+	for _, removed := range [][]string{{"a", "c"}, {"b"}} {
+		paramsCopy := params
+		paramsCopy.InputPaths = nil
+		paramsCopy.Remove = true
+		paramsCopy.Patterns = nil
+		for _, name := range removed {
+			paramsCopy.Patterns = append(paramsCopy.Patterns, path.Join(inArc, name))
+			delete(files, name)
+		}
+
+		if archive, err = arc.NewArc(paramsCopy); err != nil {
+			t.Fatal(err)
+		}
+
+		os.RemoveAll(outPath)
+		disableStdout()
+		err = archive.Remove(paramsCopy.Patterns)
+		if err == nil {
+			paramsCopy.Remove = false
+			if archive, err = arc.NewArc(paramsCopy); err == nil {
+				err = archive.Decompress(nil)
+			}
+		}
+		enableStdout()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, name := range []string{"a", "b", "c", "d"} {
+			data, err := os.ReadFile(filepath.Join(outPath, dir, name))
+			if expected, ok := files[name]; !ok && err == nil {
+				t.Errorf("Removed '%s' was decompressed", name)
+			} else if ok && !bytes.Equal(data, expected) {
+				t.Errorf("Expected %q got %q (%v)", expected, data, err)
+			}
+		}
+	}
+}
+
+func TestGzipExtract(t *testing.T) {
+	t.Cleanup(clearArcOut)
+	initRootEnts(t)
+	params.Ct = compressor.GZip
+
+	params.InputPaths = nil
+	for _, rootEnt := range rootEnts {
+		path := filepath.Join(prefix, testPath, rootEnt.Name())
+		params.InputPaths = append(params.InputPaths, path)
+	}
+
+	archive, err := arc.NewArc(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	disableStdout()
+	if err = archive.Compress(params.InputPaths); err != nil {
+		enableStdout()
+		t.Fatal(err)
+	}
+	enableStdout()
+
+	// Распаковывается только последняя директория
+	last := rootEnts[len(rootEnts)-1]
+	paramsCopy := params
+	paramsCopy.InputPaths = nil
+	paramsCopy.Extract = true
+	paramsCopy.Patterns = []string{path.Join(testPath, last.Name())}
+
+	if archive, err = arc.NewArc(paramsCopy); err != nil {
+		t.Fatal(err)
+	}
+
+	disableStdout()
+	err = archive.Decompress(paramsCopy.Patterns)
+	enableStdout()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, rootEnt := range rootEnts[:len(rootEnts)-1] {
+		skipped := filepath.Join(outPath, testPath, rootEnt.Name())
+		if _, err = os.Stat(skipped); err == nil {
+			t.Errorf("Unmatched '%s' was decompressed", skipped)
+		}
+	}
+
+	checkMD5(t, filepath.Join(prefix, testPath, last.Name()))
+}
+
+func TestMerge(t *testing.T) {
+	t.Cleanup(clearArcOut)
+	initRootEnts(t)
+
+	// Каждая директория сжимается в свой архив своим компрессором
+	var sources []string
+	for i, rootEnt := range rootEnts {
+		paramsCopy := params
+		paramsCopy.ArcPath = filepath.Join(os.TempDir(), fmt.Sprint(i, arcName))
+		paramsCopy.InputPaths = []string{filepath.Join(prefix, testPath, rootEnt.Name())}
+		paramsCopy.Ct = []compressor.Type{compressor.GZip, compressor.LZ4}[i%2]
+		t.Cleanup(func() { os.Remove(paramsCopy.ArcPath) })
+
+		archive, err := arc.NewArc(paramsCopy)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		disableStdout()
+		err = archive.Compress(paramsCopy.InputPaths)
+		enableStdout()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sources = append(sources, paramsCopy.ArcPath)
+	}
+
+	// Новый архив может заменить один из объединяемых
+	for _, out := range []string{archivePath, sources[0]} {
+		clearArcOut()
+
+		paramsCopy := params
+		paramsCopy.ArcPath = out
+		paramsCopy.InputPaths = nil
+		paramsCopy.Merge = true
+
+		archive, err := arc.NewArc(paramsCopy)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		disableStdout()
+		err = archive.Merge(sources, p.DupError)
+		if err == nil {
+			paramsCopy.Merge = false
+			if archive, err = arc.NewArc(paramsCopy); err == nil {
+				err = archive.Decompress(nil)
+			}
+		}
+		enableStdout()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, rootEnt := range rootEnts {
+			checkMD5(t, filepath.Join(prefix, testPath, rootEnt.Name()))
+		}
+	}
+}
+
+func TestTranscode(t *testing.T) {
+	t.Cleanup(clearArcOut)
+	initRootEnts(t)
+
+	// Исходный архив сжимается LZW и перекодируется в ZLib
+	paramsCopy := params
+	paramsCopy.ArcPath = filepath.Join(os.TempDir(), "lzw"+arcName)
+	paramsCopy.Ct = compressor.LempelZivWelch
+	paramsCopy.InputPaths = nil
+	for _, rootEnt := range rootEnts {
+		paramsCopy.InputPaths = append(paramsCopy.InputPaths,
+			filepath.Join(prefix, testPath, rootEnt.Name()))
+	}
+	t.Cleanup(func() { os.Remove(paramsCopy.ArcPath) })
+
+	archive, err := arc.NewArc(paramsCopy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	disableStdout()
+	err = archive.Compress(paramsCopy.InputPaths)
+	enableStdout()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Новый архив может заменить исходный
+	source := paramsCopy.ArcPath
+	for _, out := range []string{archivePath, source} {
+		clearArcOut()
+
+		paramsCopy = params
+		paramsCopy.ArcPath = out
+		paramsCopy.InputPaths = nil
+		paramsCopy.Transcode = true
+		paramsCopy.Ct, paramsCopy.Cl = compressor.ZLib, 9
+
+		if archive, err = arc.NewArc(paramsCopy); err != nil {
+			t.Fatal(err)
+		}
+
+		disableStdout()
+		err = archive.Transcode(source)
+		if err == nil {
+			paramsCopy.Transcode = false
+			if archive, err = arc.NewArc(paramsCopy); err == nil {
+				err = archive.Decompress(nil)
+			}
+		}
+		enableStdout()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, rootEnt := range rootEnts {
+			checkMD5(t, filepath.Join(prefix, testPath, rootEnt.Name()))
+		}
+	}
+}
+
+func TestMaxPathLen(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Path length limit differs on Windows")
+	}
+	t.Cleanup(clearArcOut)
+
+	// Относительный путь длиной ровно PATH_MAX без нулевого байта
+	const maxPathLen = 4095
+	var long string
+	for len(long)+201 < maxPathLen-10 {
+		long += strings.Repeat("d", 200) + "/"
+	}
+	long += strings.Repeat("f", maxPathLen-len(long))
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	if err = os.MkdirAll(filepath.Dir(long), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(long, []byte("long"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	params.Ct = compressor.GZip
+	params.InputPaths = []string{long + "x"}
+	t.Cleanup(func() { params.InputPaths = nil })
+
+	archive, err := arc.NewArc(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	disableStdout()
+	err = archive.Compress(params.InputPaths)
+	enableStdout()
+	if err == nil || !strings.Contains(err.Error(), fmt.Sprint(maxPathLen)) {
+		t.Fatalf("Path of %d bytes was not rejected: %v", len(long)+1, err)
+	}
+
+	params.InputPaths = []string{long}
+	if archive, err = arc.NewArc(params); err != nil {
+		t.Fatal(err)
+	}
+
+	// Файл распаковывается на прежнее место в текущей
+	// директории, иначе путь превысит ограничение
+	disableStdout()
+	err = archive.Compress(params.InputPaths)
+	if err == nil {
+		err = os.Remove(long)
+	}
+	if err == nil {
+		paramsCopy := params
+		paramsCopy.InputPaths = nil
+		paramsCopy.OutputDir = ""
+		if archive, err = arc.NewArc(paramsCopy); err == nil {
+			err = archive.Decompress(nil)
+		}
+	}
+	enableStdout()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data, err := os.ReadFile(long); err != nil || string(data) != "long" {
+		t.Errorf("Path of %d bytes was not restored: %v", len(long), err)
+	}
+}
+
+func TestTimesOrder(t *testing.T) {
+	t.Cleanup(clearArcOut)
+
+	var (
+		dir   = t.TempDir()
+		path  = filepath.Join(dir, "file")
+		atime = time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+		mtime = time.Date(2010, 6, 15, 12, 30, 45, 123456789, time.UTC)
+	)
+
+	if err := os.WriteFile(path, []byte("times"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Время доступа отличается от времени изменения, перепутанные
+	// при чтении заголовка метки меняют время изменения
+	if err := os.Chtimes(path, atime, mtime); err != nil {
+		t.Fatal(err)
+	}
+
+	params.Ct = compressor.GZip
+	params.InputPaths = []string{dir}
+	t.Cleanup(func() { params.InputPaths = nil })
+	baseTesting(t, dir)
+
+	info, err := os.Stat(filepath.Join(outPath, path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !info.ModTime().Equal(mtime) {
+		t.Errorf("Modification time of '%s' is %s, want %s", path, info.ModTime(), mtime)
+	}
+}
+
+func TestLinkOutsideOutDir(t *testing.T) {
+	t.Cleanup(clearArcOut)
+
+	var (
+		dir  = t.TempDir()
+		file = filepath.Join(dir, "file")
+	)
+
+	if err := os.WriteFile(file, []byte("target"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Link(file, filepath.Join(dir, "link")); err != nil {
+		t.Skip("Hard links are not supported:", err)
+	}
+
+	saved := params
+	t.Cleanup(func() { params = saved })
+	params.Ct = compressor.GZip
+	params.InputPaths = []string{dir}
+
+	archive, err := arc.NewArc(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	disableStdout()
+	err = archive.Compress(params.InputPaths)
+	enableStdout()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Путь к файлу ссылки заменяется путем той же длины к
+	// существующему файлу вне директории распаковки. Первое
+	// вхождение пути -- запись файла, второе -- запись ссылки.
+	data, err := os.ReadFile(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	target := []byte(strings.TrimPrefix(filepath.ToSlash(file), "/"))
+	outside := strings.Repeat("x", len(target)-3)
+	escape := []byte("../" + outside)
+	if err = os.WriteFile(filepath.Join(outPath, "..", outside), []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Remove(filepath.Join(outPath, "..", outside)) })
+
+	first := bytes.Index(data, target) + len(target)
+	second := first + bytes.Index(data[first:], target)
+	copy(data[second:], escape)
+
+	if err = os.WriteFile(archivePath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	paramsCopy := params
+	paramsCopy.InputPaths = nil
+	if archive, err = arc.NewArc(paramsCopy); err != nil {
+		t.Fatal(err)
+	}
+
+	disableStdout()
+	err = archive.Decompress(nil)
+	enableStdout()
+	if err == nil {
+		t.Error("Link to a file outside the output directory was restored")
+	}
+
+	if _, err = os.Stat(filepath.Join(outPath, dir, "link")); err == nil {
+		t.Errorf("Link to '%s' was created", escape)
+	}
+
+	// Прерванная распаковка не влияет на следующую
+	clearArcOut()
+	other := filepath.Join(t.TempDir(), "other")
+	if err = os.WriteFile(other, []byte("other"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	params.InputPaths = []string{other}
+	if archive, err = arc.NewArc(params); err != nil {
+		t.Fatal(err)
+	}
+	disableStdout()
+	err = archive.Compress(params.InputPaths)
+	enableStdout()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paramsCopy.InputPaths = nil
+	if archive, err = arc.NewArc(paramsCopy); err != nil {
+		t.Fatal(err)
+	}
+	disableStdout()
+	err = archive.Decompress(nil)
+	enableStdout()
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSetuidWithoutChown(t *testing.T) {
+	if runtime.GOOS == "windows" || os.Geteuid() != 0 {
+		t.Skip("Changing owner requires root")
+	}
+	t.Cleanup(clearArcOut)
+
+	var (
+		dir     = t.TempDir()
+		foreign = filepath.Join(dir, "foreign") // Файл другого владельца
+		own     = filepath.Join(dir, "own")     // Файл распаковывающего пользователя
+		special = os.ModeSetuid | os.ModeSetgid | os.ModeSticky
+	)
+
+	for _, path := range []string{foreign, own} {
+		if err := os.WriteFile(path, []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Смена владельца сбрасывает setuid, поэтому права задаются после нее
+	if err := os.Chown(foreign, 65534, 65534); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{foreign, own} {
+		if err := os.Chmod(path, 0755|os.ModeSetuid|os.ModeSetgid); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	params.Ct = compressor.GZip
+	params.InputPaths = []string{dir}
+	t.Cleanup(func() { params.InputPaths = nil })
+	baseTesting(t, dir)
+
+	for path, want := range map[string]os.FileMode{
+		foreign: 0755,
+		own:     0755 | os.ModeSetuid | os.ModeSetgid,
+	} {
+		info, err := os.Stat(filepath.Join(outPath, path))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if mode := info.Mode() & (os.ModePerm | special); mode != want {
+			t.Errorf("Mode of '%s' is %s, want %s", path, mode, want)
+		}
+	}
+}
+
 func TestNopByEntry(t *testing.T) {
 	runTestByEntry(t, compressor.Nop)
 }
@@ -69,6 +704,14 @@ func TestZlibByEntry(t *testing.T) {
 	runTestByEntry(t, compressor.ZLib)
 }
 
+func TestFlateByEntry(t *testing.T) {
+	runTestByEntry(t, compressor.Flate)
+}
+
+func TestLz4ByEntry(t *testing.T) {
+	runTestByEntry(t, compressor.LZ4)
+}
+
 func TestNopByFile(t *testing.T) {
 	runTestByFile(t, compressor.Nop)
 }
@@ -85,6 +728,14 @@ func TestZlibByFile(t *testing.T) {
 	runTestByFile(t, compressor.ZLib)
 }
 
+func TestFlateByFile(t *testing.T) {
+	runTestByFile(t, compressor.Flate)
+}
+
+func TestLz4ByFile(t *testing.T) {
+	runTestByFile(t, compressor.LZ4)
+}
+
 func runTestAll(t *testing.T, ct compressor.Type) {
 	t.Cleanup(clearArcOut)
 	initRootEnts(t)
@@ -143,7 +794,7 @@ func baseTesting(t *testing.T, path string) {
 
 	t.Logf("Testing %s decompress '%s'", params.Ct, path)
 	disableStdout()
-	if err = archive.Decompress(); err != nil {
+	if err = archive.Decompress(nil); err != nil {
 		enableStdout()
 		t.Fatal(err)
 	}
@@ -162,6 +813,11 @@ func runAll(t *testing.T) {
 	for _, rootEnt := range rootEnts {
 		checkMD5(t, filepath.Join(prefix, testPath, rootEnt.Name()))
 	}
+
+	t.Log("Comparing in/out directories")
+	for _, rootEnt := range rootEnts {
+		checkDirs(t, filepath.Join(prefix, testPath, rootEnt.Name()))
+	}
 }
 
 func runByEntry(t *testing.T) {
@@ -175,6 +831,9 @@ func runByEntry(t *testing.T) {
 
 		t.Log("Comparing MD-5 hashsum in/out files")
 		checkMD5(t, filepath.Join(prefix, testPath, rootEnt.Name()))
+
+		t.Log("Comparing in/out directories")
+		checkDirs(t, filepath.Join(prefix, testPath, rootEnt.Name()))
 	}
 	clearArcOut()
 }
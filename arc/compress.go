@@ -6,11 +6,15 @@ import (
 	"archiver/arc/internal/header"
 	"archiver/errtype"
 	"io"
-	"sort"
 )
 
-// Создает файл архива с содержимым путей path
+// Создает файл архива с содержимым путей path.
+// Существующий архив с флагом '-u' дополняется.
 func (arc Arc) Compress(paths []string) error {
+	if arc.update {
+		return arc.appendArc(paths)
+	}
+
 	var (
 		headers []header.Header
 		arcFile io.WriteCloser
@@ -20,7 +24,18 @@ func (arc Arc) Compress(paths []string) error {
 	if headers, err = compress.PrepareHeaders(paths); err != nil {
 		return errtype.ErrCompress(err)
 	}
-	sort.Sort(header.ByPathInArc(headers)) // Сортруем без учета регистра
+
+	if arc.AutoDict { // Словарь из выборки входных файлов
+		arc.Dict = compress.BuildDict(headers)
+	}
+	arc.headerLen += 4 + int64(len(arc.Dict)) // Размер и словарь
+	arc.headerLen += 2                        // Параметры LZW
+	generic.SetDictionary(arc.Dict)
+	if err = generic.SetLzwParams(arc.Lzw); err != nil {
+		return errtype.ErrCompress(
+			errtype.Join(ErrCompressorInit, err),
+		)
+	}
 
 	arcFile, err = arc.writeArcHeader() // Пишем заголовок архива
 	if err != nil {
@@ -29,7 +44,7 @@ func (arc Arc) Compress(paths []string) error {
 		)
 	}
 
-	if err = generic.InitCompressors(arc.RestoreParams); err != nil {
+	if err = generic.InitCompressors(arc.Ct, arc.Cl); err != nil {
 		return errtype.ErrCompress(
 			errtype.Join(ErrCompressorInit, err),
 		)
@@ -38,7 +53,7 @@ func (arc Arc) Compress(paths []string) error {
 	// Установка размера буфера записи
 	generic.SetWriteBufSize((generic.BufferSize() * generic.Ncpu()) << 1)
 
-	if err = compress.ProcessingHeaders(arcFile, headers); err != nil {
+	if err = compress.ProcessingHeaders(arcFile, arc.headerLen, headers, arc.RestoreParams); err != nil {
 		arc.closeRemove(arcFile)
 		return errtype.ErrCompress(err)
 	}
@@ -5,18 +5,25 @@ import (
 	"archiver/arc/internal/generic"
 	"archiver/arc/internal/header"
 	"archiver/errtype"
+	"archiver/filesystem"
+	"fmt"
 	"io"
 	"os"
+	"slices"
 )
 
 // Выполняет распаковку архива.
 //
-// Открывает файл архива, пропускает магическое число и тип
-// компрессора, затем обрабатывает содержимое архива, проходя
+// Открывает файл архива, пропускает заголовок архива,
+// затем обрабатывает содержимое архива, проходя
 // по заголовкам разного типа. Обнаруженные заголовки
 // обрабатываются соответствующими методами, а после завершения
 // работы освобождаются декомпрессоры.
-func (arc Arc) Decompress() error {
+//
+// Если заданы шаблоны patterns, то распаковываются только
+// элементы, путь которых совпадает с одним из шаблонов,
+// вместе с содержимым совпавших директорий.
+func (arc Arc) Decompress(patterns []string) error {
 	arcFile, err := os.OpenFile(arc.arcPath, os.O_RDONLY, 0644)
 	if err != nil {
 		return errtype.ErrDecompress(errtype.Join(ErrOpenArc, err))
@@ -24,21 +31,149 @@ func (arc Arc) Decompress() error {
 	defer arcFile.Close()
 
 	generic.SetWriteBufSize(generic.BufferSize() * generic.Ncpu())
+	decompress.ResetSolid()
+	if err := decompress.LoadChunks(arcFile, arc.headerLen); err != nil {
+		return errtype.ErrDecompress(err)
+	}
+
+	// Распакованные директории, атрибуты которых
+	// восстанавливаются после их содержимого
+	var dirs []*header.DirItem
+	if len(patterns) > 0 {
+		err = arc.restoreSelected(arcFile, patterns, &dirs)
+	} else {
+		err = arc.restoreAll(arcFile, &dirs)
+	}
+	if err != nil {
+		return errtype.ErrDecompress(err)
+	}
 
-	if err := generic.ProcessHeaders(arcFile, arcHeaderLen, arc.restoreHandler); err != nil {
+	if err := decompress.RestoreDirsAttrs(dirs, arc.RestoreParams); err != nil {
 		return errtype.ErrDecompress(err)
 	}
 
 	return nil
 }
 
-// Обработчик заголовков архива для распаковки
-func (arc Arc) restoreHandler(typ header.HeaderType, arcFile io.ReadSeekCloser) (err error) {
+// Распаковывает последние записи всех путей архива
+func (arc Arc) restoreAll(arcFile io.ReadSeekCloser, dirs *[]*header.DirItem) error {
+	// Распаковываются только последние записи с одинаковым путем
+	shadowed, err := decompress.ShadowedRecords(arcFile, arc.headerLen)
+	if err != nil {
+		return err
+	}
+
+	handler := func(typ header.HeaderType, arcFile io.ReadSeekCloser) error {
+		if pos, _ := arcFile.Seek(0, io.SeekCurrent); shadowed[pos-1] {
+			return decompress.SkipRecord(typ, arcFile)
+		}
+		return arc.restoreHandler(typ, arcFile, dirs)
+	}
+
+	return generic.ProcessHeaders(arcFile, arc.headerLen, handler)
+}
+
+// Распаковывает последние записи путей, совпадающих с
+// шаблонами patterns. Если в архиве есть индекс, то
+// выполняется переход сразу к выбранным записям, иначе
+// остальные записи пропускаются при чтении архива.
+func (arc Arc) restoreSelected(arcFile io.ReadSeekCloser, patterns []string, dirs *[]*header.DirItem) error {
+	entries, err := decompress.ReadEntries(arcFile, arc.headerLen)
+	if err != nil {
+		return errtype.Join(ErrReadHeaders, err)
+	}
+	selected := selectEntries(header.LatestEntries(entries), patterns)
+
+	var indexed bool
+	if header.HasFlag(header.FlagIndex) {
+		if _, indexed, err = decompress.IndexOffset(arcFile, arc.headerLen); err != nil {
+			return errtype.Join(ErrReadIndex, err)
+		}
+	}
+
+	if !indexed {
+		handler := func(typ header.HeaderType, arcFile io.ReadSeekCloser) error {
+			if pos, _ := arcFile.Seek(0, io.SeekCurrent); !selected[pos-1] {
+				return decompress.SkipRecord(typ, arcFile)
+			}
+			return arc.restoreHandler(typ, arcFile, dirs)
+		}
+
+		return generic.ProcessHeaders(arcFile, arc.headerLen, handler)
+	}
+
+	// Файлы жестких ссылок записаны раньше ссылок
+	offsets := make([]int64, 0, len(selected))
+	for offset := range selected {
+		offsets = append(offsets, offset)
+	}
+	slices.Sort(offsets)
+
+	var typ header.HeaderType
+	for _, offset := range offsets {
+		arcFile.Seek(offset, io.SeekStart)
+		if err = filesystem.BinaryRead(arcFile, &typ); err != nil {
+			return errtype.Join(ErrReadHeaderType, err)
+		}
+
+		if err = arc.restoreHandler(typ, arcFile, dirs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Выбирает из записей entries совпадающие с шаблонами
+// patterns и возвращает их смещения. Для выбранных
+// жестких ссылок выбираются и их файлы. Печатает
+// шаблоны, с которыми не совпал ни один элемент.
+func selectEntries(entries []header.IndexEntry, patterns []string) map[int64]bool {
+	var (
+		matched  = make([]bool, len(patterns))
+		selected = map[int64]bool{}
+		offsets  = make(map[string]int64, len(entries)) // Смещения записей по пути
+		targets  []string                               // Файлы выбранных жестких ссылок
+	)
+
+	for _, e := range entries {
+		offsets[e.Header.PathInArc()] = e.Offset
+		if matchPath(patterns, e.Header.PathInArc(), matched) {
+			selected[e.Offset] = true
+			if li, ok := e.Header.(*header.LinkItem); ok {
+				targets = append(targets, li.PathOnDisk())
+			}
+		}
+	}
+	printUnmatched(patterns, matched)
+
+	// Жесткая ссылка создается на уже распакованный файл
+	for _, target := range targets {
+		if offset, ok := offsets[target]; ok && !selected[offset] {
+			selected[offset] = true
+			fmt.Printf("Распаковывается файл жесткой ссылки %s\n", target)
+		}
+	}
+
+	return selected
+}
+
+// Обработчик заголовков архива для распаковки,
+// созданные директории добавляются в dirs
+func (arc Arc) restoreHandler(typ header.HeaderType, arcFile io.ReadSeekCloser, dirs *[]*header.DirItem) (err error) {
 	switch typ {
 	case header.File:
 		err = decompress.RestoreFile(arcFile, arc.RestoreParams)
 	case header.Symlink:
 		err = decompress.RestoreSym(arcFile, arc.RestoreParams)
+	case header.Directory:
+		err = decompress.RestoreDir(arcFile, arc.RestoreParams, dirs)
+	case header.HardLink:
+		err = decompress.RestoreLink(arcFile, arc.RestoreParams)
+	case header.Fifo:
+		err = decompress.RestoreSpecial(arcFile, &header.FifoItem{}, arc.RestoreParams)
+	case header.Device:
+		err = decompress.RestoreSpecial(arcFile, &header.DeviceItem{}, arc.RestoreParams)
 	default:
 		return ErrHeaderType
 	}
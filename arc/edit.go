@@ -0,0 +1,279 @@
+package arc
+
+import (
+	"archiver/arc/internal/compress"
+	"archiver/arc/internal/decompress"
+	"archiver/arc/internal/generic"
+	"archiver/arc/internal/header"
+	"archiver/errtype"
+	"archiver/filesystem"
+	"fmt"
+	"io"
+	"os"
+	"path"
+)
+
+// Изменяет записи архива. Возвращает оставшиеся записи
+// и признак того, что архив нужно переписать.
+type editFunc func(records []compress.Record) ([]compress.Record, bool, error)
+
+// Удаляет из архива элементы, путь которых совпадает с
+// одним из шаблонов patterns, вместе с содержимым
+// совпавших директорий. Печатает шаблоны, с которыми
+// не совпал ни один элемент.
+func (arc Arc) Remove(patterns []string) error {
+	return arc.rewrite(func(records []compress.Record) ([]compress.Record, bool, error) {
+		var (
+			matched      = make([]bool, len(patterns))
+			kept, remove []compress.Record
+		)
+
+		for _, r := range records {
+			if matchPath(patterns, r.Header.PathInArc(), matched) {
+				remove = append(remove, r)
+			} else {
+				kept = append(kept, r)
+			}
+		}
+		printUnmatched(patterns, matched)
+
+		if err := checkRemove(remove, kept); err != nil {
+			return nil, false, err
+		}
+
+		for _, r := range remove {
+			fmt.Println("Удален", r.Header.PathInArc())
+		}
+
+		return kept, len(remove) > 0, nil
+	})
+}
+
+// Переименовывает элементы архива, заменяя префиксы путей
+// по парам pairs: старый префикс, новый префикс
+func (arc Arc) Rename(pairs []string) error {
+	return arc.rewrite(func(records []compress.Record) ([]compress.Record, bool, error) {
+		var (
+			paths   = make(map[string]bool, len(records))
+			targets = map[string]string{} // Прежние пути по новым
+			changed bool
+		)
+
+		for _, r := range records {
+			paths[r.Header.PathInArc()] = true
+		}
+
+		for _, r := range records {
+			old := r.Header.PathInArc()
+
+			for i := 0; i < len(pairs); i += 2 {
+				ok, err := header.RenamePrefix(r.Header, pairs[i], pairs[i+1])
+				if err != nil {
+					return nil, false, err
+				} else if !ok {
+					continue
+				}
+				changed = true // Мог измениться только файл жесткой ссылки
+
+				if p := r.Header.PathInArc(); p != old {
+					// Дописанные с -u версии элемента переименовываются вместе
+					if from, ok := targets[p]; paths[p] || ok && from != old {
+						return nil, false, ErrRenameExists(p)
+					} else if !ok {
+						fmt.Println(old, "->", p)
+					}
+					targets[p] = old
+				}
+				break
+			}
+		}
+
+		if !changed {
+			fmt.Println("Нет элементов для переименования")
+		}
+
+		return records, changed, nil
+	})
+}
+
+// Переписывает архив во временный файл рядом с ним,
+// перенося оставшиеся после edit записи без распаковки,
+// и атомарно заменяет им архив
+func (arc Arc) rewrite(edit editFunc) error {
+	arcFile, err := os.Open(arc.arcPath)
+	if err != nil {
+		return errtype.ErrRuntime(errtype.Join(ErrOpenArc, err))
+	}
+	defer arcFile.Close()
+
+	entries, err := decompress.ReadEntries(arcFile, arc.headerLen)
+	if err != nil {
+		return errtype.ErrRuntime(errtype.Join(ErrReadHeaders, err))
+	}
+
+	end, err := decompress.RecordsEnd(arcFile, arc.headerLen)
+	if err != nil {
+		return errtype.ErrRuntime(errtype.Join(ErrEditArc, err))
+	}
+
+	all := compress.Records(entries, end)
+	records, changed, err := edit(all)
+	if err != nil {
+		return errtype.ErrRuntime(errtype.Join(ErrEditArc, err))
+	} else if !changed {
+		return nil
+	}
+
+	if err = arc.writeRecords(arcFile, all, records); err != nil {
+		arc.RemoveTmp()
+		return errtype.ErrRuntime(errtype.Join(ErrEditArc, err))
+	}
+
+	return nil
+}
+
+// Пишет заголовок архива и записи records из arcFile во
+// временный файл, после чего заменяет им архив. Solid-группы,
+// часть файлов которых не вошла в records из всех записей
+// all, сжимаются заново из оставшихся файлов.
+func (arc Arc) writeRecords(arcFile *os.File, all, records []compress.Record) error {
+	info, err := arcFile.Stat()
+	if err != nil {
+		return err
+	}
+
+	tmpFile, err := os.OpenFile(arc.tmpPath(), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode())
+	if err != nil {
+		return errtype.Join(ErrCreateArc, err)
+	}
+	defer tmpFile.Close()
+
+	// Заголовок архива переносится как есть
+	arcFile.Seek(0, io.SeekStart)
+	if _, err = io.CopyN(tmpFile, arcFile, arc.headerLen); err != nil {
+		return err
+	}
+
+	generic.SetWriteBufSize((generic.BufferSize() * generic.Ncpu()) << 1)
+	cp := compress.NewCopier(tmpFile, arc.headerLen)
+	cp.Regroup(arcFile, all, records)
+	for _, r := range records {
+		if err = cp.Copy(arcFile, r); err != nil {
+			return err
+		}
+	}
+
+	if err = cp.Finish(header.HasFlag(header.FlagIndex)); err != nil {
+		return err
+	}
+
+	if err = tmpFile.Sync(); err != nil {
+		return err
+	}
+
+	return os.Rename(arc.tmpPath(), arc.arcPath)
+}
+
+// Путь к временному файлу изменяемого архива
+func (arc Arc) tmpPath() string {
+	return arc.arcPath + ".tmp"
+}
+
+// Сбрасывает на диск и закрывает временный
+// файл tmpFile, после чего заменяет им архив
+func (arc Arc) replaceWithTmp(tmpFile *os.File) error {
+	if err := tmpFile.Sync(); err != nil {
+		tmpFile.Close()
+		return err
+	}
+
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(arc.tmpPath(), arc.arcPath)
+}
+
+// Проверяет, что удаляемые записи remove не нужны
+// оставшимся записям kept: фрагменты при дедупликации
+// и файлы жестких ссылок
+func checkRemove(remove, kept []compress.Record) error {
+	var (
+		refs   = map[header.ChunkHash]bool{}   // Фрагменты, нужные оставшимся файлам
+		links  = map[string]string{}           // Файлы оставшихся жестких ссылок
+		stored = map[header.ChunkHash]string{} // Фрагменты удаляемых файлов
+	)
+
+	for _, r := range kept {
+		switch h := r.Header.(type) {
+		case *header.FileItem:
+			for _, ch := range h.Chunks() {
+				refs[ch.Hash] = true
+			}
+		case *header.LinkItem:
+			links[h.PathOnDisk()] = h.PathInArc()
+		}
+	}
+
+	// Фрагменты, хранящиеся в оставшихся файлах, не теряются
+	for _, r := range kept {
+		if fi, ok := r.Header.(*header.FileItem); ok {
+			for _, ch := range fi.Chunks() {
+				if ch.Stored {
+					delete(refs, ch.Hash)
+				}
+			}
+		}
+	}
+
+	for _, r := range remove {
+		if link, ok := links[r.Header.PathInArc()]; ok {
+			return ErrRemoveLinkTarget(r.Header.PathInArc(), link)
+		}
+
+		fi, ok := r.Header.(*header.FileItem)
+		if !ok {
+			continue
+		}
+
+		for _, ch := range fi.Chunks() {
+			if ch.Stored {
+				stored[ch.Hash] = fi.PathInArc()
+			}
+		}
+	}
+
+	for hash := range refs {
+		if path, ok := stored[hash]; ok {
+			return ErrRemoveChunks(path)
+		}
+	}
+
+	return nil
+}
+
+// Сообщает, совпадает ли путь p или одна из его родительских
+// директорий с одним из шаблонов patterns. Отмечает в
+// matched все совпавшие шаблоны.
+func matchPath(patterns []string, p string, matched []bool) (ok bool) {
+	p = filesystem.Clean(p)
+	for i, pattern := range patterns {
+		for dir := p; dir != "." && dir != "/" && dir != ""; dir = path.Dir(dir) {
+			if m, _ := path.Match(pattern, dir); m {
+				matched[i], ok = true, true
+				break
+			}
+		}
+	}
+
+	return ok
+}
+
+// Печатает шаблоны, с которыми не совпал ни один элемент
+func printUnmatched(patterns []string, matched []bool) {
+	for i, pattern := range patterns {
+		if !matched[i] {
+			fmt.Printf("Шаблон '%s' не совпал ни с одним элементом\n", pattern)
+		}
+	}
+}
@@ -4,9 +4,32 @@ import "archiver/arc/internal/errors"
 
 // Ошибки при открытии архива
 var (
-	ErrIsDir       = errors.ErrIsDir
-	ErrNotArc      = errors.ErrNotArc
-	ErrUnknownComp = errors.ErrUnknownComp
+	ErrIsDir            = errors.ErrIsDir
+	ErrNotArc           = errors.ErrNotArc
+	ErrVersion          = errors.ErrVersion
+	ErrUnsupportedFlags = errors.ErrUnsupportedFlags
+	ErrUnknownComp      = errors.ErrUnknownComp
+)
+
+// Ошибки при дополнении архива
+var (
+	ErrUpdateFormat   = errors.ErrUpdateFormat
+	ErrUpdateCompType = errors.ErrUpdateCompType
+	ErrUpdateArc      = errors.ErrUpdateArc
+)
+
+// Ошибки при изменении архива
+var (
+	ErrEditArc          = errors.ErrEditArc
+	ErrRemoveChunks     = errors.ErrRemoveChunks
+	ErrRemoveLinkTarget = errors.ErrRemoveLinkTarget
+	ErrRenameExists     = errors.ErrRenameExists
+	ErrMergeArc         = errors.ErrMergeArc
+	ErrMergeFormat      = errors.ErrMergeFormat
+	ErrMergeDict        = errors.ErrMergeDict
+	ErrMergeLzw         = errors.ErrMergeLzw
+	ErrMergeDuplicate   = errors.ErrMergeDuplicate
+	ErrTranscodeArc     = errors.ErrTranscodeArc
 )
 
 // Ошибки при сжатии
@@ -20,6 +43,8 @@ var (
 	ErrReadHeaders    = errors.ErrReadHeaders
 	ErrDecompressFile = errors.ErrDecompressFile
 	ErrDecompressSym  = errors.ErrDecompressSym
+	ErrDecompressDir  = errors.ErrDecompressDir
+	ErrDecompressLink = errors.ErrDecompressLink
 )
 
 // Ошибки проверки целостности
@@ -31,17 +56,28 @@ var (
 
 // Ошибки функции чтения
 var (
-	ErrOpenArc        = errors.ErrOpenArc
-	ErrReadMagic      = errors.ErrReadMagic
-	ErrReadFileHeader = errors.ErrReadFileHeader
-	ErrReadSymHeader  = errors.ErrReadSymHeader
-	ErrReadHeaderType = errors.ErrReadHeaderType
-	ErrHeaderType     = errors.ErrHeaderType
+	ErrOpenArc           = errors.ErrOpenArc
+	ErrReadMagic         = errors.ErrReadMagic
+	ErrReadArcHeader     = errors.ErrReadArcHeader
+	ErrReadFileHeader    = errors.ErrReadFileHeader
+	ErrReadSymHeader     = errors.ErrReadSymHeader
+	ErrReadDirHeader     = errors.ErrReadDirHeader
+	ErrReadLinkHeader    = errors.ErrReadLinkHeader
+	ErrReadSpecialHeader = errors.ErrReadSpecialHeader
+	ErrReadHeaderType    = errors.ErrReadHeaderType
+	ErrHeaderType        = errors.ErrHeaderType
+	ErrReadIndex         = errors.ErrReadIndex
+	ErrReadDict          = errors.ErrReadDict
+	ErrReadLzwParams     = errors.ErrReadLzwParams
+	ErrDictSize          = errors.ErrDictSize
 )
 
 // Ошибки функции записи
 var (
 	ErrCreateArc     = errors.ErrCreateArc
 	ErrWriteMagic    = errors.ErrWriteMagic
+	ErrWriteVersion  = errors.ErrWriteVersion
 	ErrWriteCompType = errors.ErrWriteCompType
+	ErrWriteDict     = errors.ErrWriteDict
+	ErrWriteLzw      = errors.ErrWriteLzw
 )
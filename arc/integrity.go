@@ -20,10 +20,14 @@ func (arc Arc) IntegrityTest() error {
 	}
 	defer arcFile.Close()
 
-	// Пропускаем магическое число и тип компрессора
-	arcFile.Seek(arcHeaderLen, io.SeekStart)
+	// Пропускаем заголовок архива
+	arcFile.Seek(arc.headerLen, io.SeekStart)
+	clear(damagedGroups)
+	if err = decompress.LoadChunks(arcFile, arc.headerLen); err != nil {
+		return errtype.ErrIntegrity(err)
+	}
 
-	err = generic.ProcessHeaders(arcFile, arcHeaderLen, arc.integrityHeaderHandler)
+	err = generic.ProcessHeaders(arcFile, arc.headerLen, arc.integrityHeaderHandler)
 	if err != nil {
 		return errtype.ErrIntegrity(err)
 	}
@@ -47,12 +51,44 @@ func (arc Arc) integrityHeaderHandler(typ header.HeaderType, arcFile io.ReadSeek
 				errtype.Join(ErrReadSymHeader, err),
 			)
 		}
+	case header.Directory:
+		di := &header.DirItem{} // Данных у директории нет
+		if err = di.Read(arcFile); err != nil && err != io.EOF {
+			return errtype.ErrIntegrity(
+				errtype.Join(ErrReadDirHeader, err),
+			)
+		}
+	case header.HardLink:
+		li := &header.LinkItem{} // Данные хранятся в записи файла
+		if err = li.Read(arcFile); err != nil && err != io.EOF {
+			return errtype.ErrIntegrity(
+				errtype.Join(ErrReadLinkHeader, err),
+			)
+		}
+	case header.Fifo:
+		pi := &header.FifoItem{} // Данных у канала нет
+		if err = pi.Read(arcFile); err != nil && err != io.EOF {
+			return errtype.ErrIntegrity(
+				errtype.Join(ErrReadSpecialHeader, err),
+			)
+		}
+	case header.Device:
+		vi := &header.DeviceItem{} // Данных у устройства нет
+		if err = vi.Read(arcFile); err != nil && err != io.EOF {
+			return errtype.ErrIntegrity(
+				errtype.Join(ErrReadSpecialHeader, err),
+			)
+		}
 	default:
 		return errtype.ErrIntegrity(ErrHeaderType)
 	}
 	return nil
 }
 
+// Признаки повреждения проверенных solid-групп
+// по смещению записи их первого файла
+var damagedGroups = map[int64]bool{}
+
 // Распаковывает файл с проверкой CRC каждого блока сжатых данных
 func (arc Arc) checkFile(arcFile io.ReadSeeker) (err error) {
 	fi := &header.FileItem{}
@@ -60,10 +96,26 @@ func (arc Arc) checkFile(arcFile io.ReadSeeker) (err error) {
 		return errtype.Join(ErrReadFileHeader, err)
 	}
 
-	if _, err = decompress.CheckCRC(arcFile, arc.Ct); err == ErrWrongCRC {
+	damaged := false
+	if fi.HasData() {
+		_, err = decompress.CheckCRC(arcFile, fi.CompType())
+		if damaged = err == ErrWrongCRC; err != nil && !damaged {
+			return errtype.Join(ErrCheckCRC, err)
+		}
+
+		if fi.Solid().Kind == header.SolidHead {
+			damagedGroups[fi.Solid().Group] = damaged
+		}
+	} else { // Данные проверены вместе с первым файлом группы
+		damaged = damagedGroups[fi.Solid().Group]
+	}
+
+	if !damaged && len(fi.Chunks()) > 0 { // Фрагменты в данных других файлов
+		damaged = decompress.CheckChunks(fi, arcFile)
+	}
+
+	if damaged {
 		fmt.Println(fi.PathOnDisk() + ": Файл поврежден")
-	} else if err != nil {
-		return errtype.Join(ErrCheckCRC, err)
 	} else {
 		fmt.Println(fi.PathOnDisk() + ": OK")
 	}
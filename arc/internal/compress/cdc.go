@@ -0,0 +1,116 @@
+package compress
+
+import (
+	"archiver/arc/internal/header"
+	"archiver/errtype"
+	"bufio"
+	"crypto/sha256"
+	"io"
+	"os"
+)
+
+// Границы длины фрагментов дедупликации
+const (
+	chunkMin = 2 << 10
+	chunkAvg = 8 << 10
+	chunkMax = 64 << 10
+)
+
+// Маски точки разреза до и после средней длины
+// фрагмента. В старших битах хеша учтены
+// последние 64 байта.
+const (
+	maskS uint64 = (1<<15 - 1) << (64 - 15)
+	maskL uint64 = (1<<11 - 1) << (64 - 11)
+)
+
+var (
+	// Случайные значения байтов для скользящего хеша
+	gear [256]uint64
+	// Хеши фрагментов, записанных в архив
+	chunkTable map[header.ChunkHash]struct{}
+)
+
+// Возвращает длину первого фрагмента data по
+// алгоритму FastCDC. Фрагмент короче chunkMin
+// получается только в конце данных.
+func cutPoint(data []byte) int {
+	n := len(data)
+	if n <= chunkMin {
+		return n
+	}
+	n = min(n, chunkMax)
+
+	var (
+		fp     uint64
+		i      = chunkMin
+		normal = min(chunkAvg, n)
+	)
+
+	for ; i < normal; i++ {
+		if fp = fp<<1 + gear[data[i]]; fp&maskS == 0 {
+			return i + 1
+		}
+	}
+
+	for ; i < n; i++ {
+		if fp = fp<<1 + gear[data[i]]; fp&maskL == 0 {
+			return i + 1
+		}
+	}
+
+	return n
+}
+
+// Делит файл fi на фрагменты и сохраняет их в заголовке.
+// Фрагменты, которых нет в chunkTable, хранятся в данных
+// файла и добавляются в таблицу.
+func splitChunks(fi *header.FileItem) error {
+	inFile, err := os.Open(fi.PathOnDisk())
+	if err != nil {
+		return errtype.Join(ErrOpenFileCompress(fi.PathOnDisk()), err)
+	}
+	defer inFile.Close()
+
+	var (
+		inBuf  = bufio.NewReaderSize(inFile, chunkMax)
+		chunks []header.Chunk
+		size   header.Size
+	)
+
+	for {
+		data, err := inBuf.Peek(chunkMax)
+		if len(data) == 0 {
+			break
+		} else if err != nil && err != io.EOF {
+			return errtype.Join(ErrReadUncompressed, err)
+		}
+
+		n := cutPoint(data)
+		ch := header.Chunk{Hash: sha256.Sum256(data[:n]), Length: uint32(n)}
+		if _, ok := chunkTable[ch.Hash]; !ok {
+			chunkTable[ch.Hash] = struct{}{}
+			ch.Stored = true
+		}
+
+		chunks = append(chunks, ch)
+		size += header.Size(n)
+		inBuf.Discard(n)
+	}
+
+	fi.SetUcSize(size) // Файл мог измениться
+	fi.SetChunks(chunks)
+
+	return nil
+}
+
+func init() {
+	seed := uint64(0x9E3779B97F4A7C15)
+	for i := range gear { // splitmix64
+		seed += 0x9E3779B97F4A7C15
+		z := seed
+		z = (z ^ z>>30) * 0xBF58476D1CE4E5B9
+		z = (z ^ z>>27) * 0x94D049BB133111EB
+		gear[i] = z ^ z>>31
+	}
+}
@@ -0,0 +1,84 @@
+package compress
+
+import (
+	"archiver/arc/internal/generic"
+	"archiver/arc/internal/header"
+	c "archiver/compressor"
+	"archiver/errtype"
+	"bytes"
+	"io"
+	"os"
+)
+
+// Размер выборки из начала файла для пробного сжатия
+const sampleSize int64 = 128 * 1024
+
+// Компрессор для пробного сжатия выборки
+var trial struct {
+	w      *c.Writer
+	ct     c.Type
+	cl     c.Level
+	dict   []byte
+	sample bytes.Buffer
+	out    bytes.Buffer
+}
+
+// Выбирает компрессор для файла fi. Файлы сжимаются
+// компрессором архива. Если установлен rp.AutoStore, то
+// без сжатия сохраняются файлы, начало которых при
+// пробном сжатии не уменьшается в размере.
+func selectCodec(fi *header.FileItem, rp generic.RestoreParams) error {
+	fi.SetCodec(rp.Ct, rp.Cl)
+
+	if !rp.AutoStore || rp.Ct == c.Nop || fi.UcSize() == 0 {
+		return nil
+	}
+
+	compressible, err := trialCompress(fi.PathOnDisk(), rp.Ct, rp.Cl)
+	if err != nil {
+		return err
+	} else if !compressible {
+		fi.SetCodec(c.Nop, c.NoCompression)
+	}
+
+	return nil
+}
+
+// Сжимает выборку из начала файла path и сообщает,
+// уменьшился ли ее размер
+func trialCompress(path string, ct c.Type, cl c.Level) (bool, error) {
+	inFile, err := os.Open(path)
+	if err != nil {
+		return false, errtype.Join(ErrOpenFileCompress(path), err)
+	}
+	defer inFile.Close()
+
+	trial.sample.Reset()
+	trial.out.Reset()
+
+	read, err := io.CopyN(&trial.sample, inFile, sampleSize)
+	if err != nil && err != io.EOF {
+		return false, errtype.Join(ErrReadUncompressBuf, err)
+	} else if read == 0 {
+		return true, nil
+	}
+
+	dict := generic.Dictionary()
+	if trial.w == nil || trial.ct != ct || trial.cl != cl || !bytes.Equal(trial.dict, dict) {
+		if trial.w, err = c.NewWriterDict(ct, &trial.out, cl, dict); err != nil {
+			return false, errtype.Join(ErrCompressorInit, err)
+		}
+		trial.ct, trial.cl, trial.dict = ct, cl, dict
+	} else {
+		trial.w.Reset(&trial.out)
+	}
+
+	if _, err = trial.sample.WriteTo(trial.w); err != nil {
+		return false, errtype.Join(ErrWriteCompressor, err)
+	}
+	if err = trial.w.Close(); err != nil {
+		return false, errtype.Join(ErrCloseCompressor, err)
+	}
+
+	return int64(trial.out.Len()) < read, nil
+}
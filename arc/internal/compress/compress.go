@@ -11,6 +11,8 @@ import (
 	"io"
 	"log"
 	"os"
+	"slices"
+	"sort"
 	"sync"
 )
 
@@ -28,31 +30,166 @@ func PrepareHeaders(paths []string) (headers []header.Header, err error) {
 	if len(headers) == 0 { // Если true, то сжимать нечего
 		return nil, ErrNoEntries
 	}
+
+	sort.Sort(header.ByPathInArc(headers)) // Сортруем без учета регистра
+	headers = linkHardlinks(headers)
+
 	return headers, nil
 }
 
-// Обработка заголовков
-func ProcessingHeaders(arcFile io.WriteCloser, headers []header.Header) error {
-	arcBuf := bufio.NewWriter(arcFile)
+// Обработка заголовков.
+//
+// После записей элементов пишет индекс архива и
+// завершающий блок со смещением индекса. arcLenH --
+// длина заголовка архива, с которой начинаются записи.
+// Из rp берутся тип компрессора и уровень сжатия архива.
+func ProcessingHeaders(arcFile io.WriteCloser, arcLenH int64, headers []header.Header, rp generic.RestoreParams) error {
+	return AppendHeaders(arcFile, arcLenH, nil, headers, rp)
+}
+
+// Дописывает записи элементов headers после записей
+// entries, уже находящихся в архиве. offset -- смещение
+// конца записей, на котором стоит arcFile. Новый индекс
+// архива включает записи entries.
+func AppendHeaders(arcFile io.WriteCloser, offset int64, entries []header.IndexEntry, headers []header.Header, rp generic.RestoreParams) error {
+	var (
+		buf    = bufio.NewWriter(arcFile)
+		arcBuf = &countWriter{w: buf, n: offset}
+		group  solidGroup
+	)
+
+	entries = slices.Clip(entries)
+	chunkTable = nil
+	if rp.Dedup { // Новые файлы ссылаются и на записанные фрагменты
+		chunkTable = map[header.ChunkHash]struct{}{}
+		for _, e := range entries {
+			if fi, ok := e.Header.(*header.FileItem); ok {
+				for _, ch := range fi.Chunks() {
+					if ch.Stored {
+						chunkTable[ch.Hash] = struct{}{}
+					}
+				}
+			}
+		}
+	}
+
 	for _, h := range headers { // Перебираем заголовки
+		if !rp.Solid {
+			if err := processingHeader(h, arcBuf, rp, &entries); err != nil {
+				return err
+			}
+			continue
+		}
+
 		if fi, ok := h.(*header.FileItem); ok {
-			if err := processingFile(fi, arcBuf); err != nil {
+			if err := prepareFile(fi, rp); err != nil {
 				return err
 			}
-		} else if di, ok := h.(*header.DirItem); ok {
-			processingDir(di)
-		} else if si, ok := h.(*header.SymItem); ok {
-			processingSym(si, arcBuf)
+
+			if group.fits(fi) {
+				group.add(fi)
+				continue
+			}
+		} else if len(group.pending) > 0 { // Сохраняем порядок записей
+			group.pending = append(group.pending, h)
+			continue
+		}
+
+		if err := group.flush(arcBuf, &entries); err != nil {
+			return err
+		}
+
+		if group.fits(h) { // Файл открывает новую группу
+			group.add(h.(*header.FileItem))
+		} else if err := writeHeader(h, arcBuf, &entries); err != nil {
+			return err
+		}
+	}
+
+	if err := group.flush(arcBuf, &entries); err != nil {
+		return err
+	}
+
+	indexOffset := arcBuf.n
+	if err := header.WriteIndex(arcBuf, entries); err != nil {
+		return errtype.Join(ErrWriteIndex, err)
+	}
+	if err := header.WriteFooter(arcBuf, indexOffset); err != nil {
+		return errtype.Join(ErrWriteIndex, err)
+	}
+	log.Println("Записан индекс по смещению:", indexOffset)
+
+	return buf.Flush()
+}
+
+// Обрабатывает заголовок h и добавляет его в индекс entries
+func processingHeader(h header.Header, arcBuf *countWriter, rp generic.RestoreParams, entries *[]header.IndexEntry) error {
+	if fi, ok := h.(*header.FileItem); ok {
+		if err := prepareFile(fi, rp); err != nil {
+			return err
+		}
+	}
+
+	return writeHeader(h, arcBuf, entries)
+}
+
+// Пишет запись элемента h, файлы должны быть подготовлены
+// [prepareFile]. Добавляет элемент в индекс entries.
+func writeHeader(h header.Header, arcBuf *countWriter, entries *[]header.IndexEntry) (err error) {
+	offset := arcBuf.n
+
+	if fi, ok := h.(*header.FileItem); ok {
+		err = processingFile(fi, arcBuf)
+	} else if di, ok := h.(*header.DirItem); ok {
+		err = processingDir(di, arcBuf)
+	} else if si, ok := h.(*header.SymItem); ok {
+		err = processingSym(si, arcBuf)
+	} else if li, ok := h.(*header.LinkItem); ok {
+		err = processingLink(li, arcBuf)
+	} else if pi, ok := h.(*header.FifoItem); ok {
+		err = processingSpecial(pi, arcBuf)
+	} else if vi, ok := h.(*header.DeviceItem); ok {
+		err = processingSpecial(vi, arcBuf)
+	} else {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+	*entries = append(*entries, header.IndexEntry{Offset: offset, Header: h})
+
+	return nil
+}
+
+// Выбирает компрессор файла, определяет области данных
+// разреженного файла и делит на фрагменты при дедупликации
+func prepareFile(fi *header.FileItem, rp generic.RestoreParams) error {
+	if err := selectCodec(fi, rp); err != nil {
+		return errtype.Join(ErrCompressFile, err)
+	}
+
+	if err := detectSparse(fi); err != nil {
+		return errtype.Join(ErrCompressFile, err)
+	}
+
+	if rp.Dedup && fi.UcSize() > 0 && len(fi.Sparse()) == 0 {
+		if err := splitChunks(fi); err != nil {
+			return errtype.Join(ErrCompressFile, err)
 		}
 	}
-	arcBuf.Flush()
+
 	return nil
 }
 
 // Обрабатывает заголовок файла
 func processingFile(fi *header.FileItem, arcBuf io.Writer) error {
-	err := fi.Write(arcBuf)
+	err := generic.InitCompressors(fi.CompType(), fi.CompLevel())
 	if err != nil {
+		return errtype.Join(ErrCompressorInit, err)
+	}
+
+	if err = fi.Write(arcBuf); err != nil {
 		return errtype.Join(ErrWriteFileHeader, err)
 	}
 
@@ -63,7 +200,10 @@ func processingFile(fi *header.FileItem, arcBuf io.Writer) error {
 }
 
 // Обрабатывает заголовок директории
-func processingDir(di *header.DirItem) error {
+func processingDir(di *header.DirItem, arcBuf io.Writer) error {
+	if err := di.Write(arcBuf); err != nil {
+		return errtype.Join(ErrWriteDirHeader, err)
+	}
 	fmt.Println(di.PathInArc())
 	return nil
 }
@@ -75,25 +215,71 @@ func processingSym(si *header.SymItem, arcBuf io.Writer) error {
 	return nil
 }
 
+// Обрабатывает заголовок жесткой ссылки
+func processingLink(li *header.LinkItem, arcBuf io.Writer) error {
+	if err := li.Write(arcBuf); err != nil {
+		return errtype.Join(ErrWriteLinkHeader, err)
+	}
+	fmt.Println(li.PathInArc(), "=>", li.PathOnDisk())
+	return nil
+}
+
+// Заголовок именованного канала или устройства
+type specialItem interface {
+	header.Header
+	Write(io.Writer) error
+}
+
+// Обрабатывает заголовок именованного канала или устройства
+func processingSpecial(sp specialItem, arcBuf io.Writer) error {
+	if err := sp.Write(arcBuf); err != nil {
+		return errtype.Join(ErrWriteSpecialHeader, err)
+	}
+	fmt.Println(sp.PathInArc())
+	return nil
+}
+
 // Сжимает файл блоками
-func compressFile(fi header.PathProvider, arcBuf io.Writer) error {
+func compressFile(fi *header.FileItem, arcBuf io.Writer) error {
 	inFile, err := os.Open(fi.PathOnDisk())
 	if err != nil {
 		return errtype.Join(ErrOpenFileCompress(fi.PathOnDisk()), err)
 	}
 	defer inFile.Close()
-	inBuf := bufio.NewReader(inFile)
 
+	var in io.Reader = inFile
+	if regions := fi.Sparse(); len(regions) > 0 {
+		in = sparseReader(inFile, regions) // Сжимаем только области данных
+	} else if len(fi.Chunks()) > 0 { // Сжимаем только новые фрагменты
+		in = sparseReader(inFile, fi.StoredRegions())
+	}
+
+	cSize, crc, err := compressData(bufio.NewReader(in), arcBuf)
+	if err != nil {
+		return err
+	}
+
+	fi.SetCSize(header.Size(cSize))
+	fi.SetCRC(crc)
+
+	fmt.Println(fi.PathInArc())
+
+	return nil
+}
+
+// Сжимает данные из inBuf блоками и пишет их в arcBuf
+// вместе с признаком конца данных и контрольной суммой.
+// Возвращает размер сжатых данных и контрольную сумму.
+func compressData(inBuf io.Reader, arcBuf io.Writer) (cSize int64, crc uint32, err error) {
 	var (
 		wrote, read int64
-		crc         uint32
 		wg          = sync.WaitGroup{}
 	)
 
 	for {
 		// Заполняем буферы несжатыми частями (блоками) файла
 		if read, err = loadUncompressedBuf(inBuf); err != nil {
-			return errtype.Join(ErrReadUncompressed, err)
+			return 0, 0, errtype.Join(ErrReadUncompressed, err)
 		}
 
 		wg.Wait()
@@ -106,7 +292,7 @@ func compressFile(fi header.PathProvider, arcBuf io.Writer) error {
 
 		// Сжимаем буферы
 		if err = compressBuffers(); err != nil {
-			return errtype.Join(ErrCompress, err)
+			return 0, 0, errtype.Join(ErrCompress, err)
 		}
 
 		var (
@@ -122,14 +308,15 @@ func compressFile(fi header.PathProvider, arcBuf io.Writer) error {
 			// Пишем длину сжатого блока
 			length := int64(compressedBuf[i].Len())
 			if err = filesystem.BinaryWrite(writeBuf, length); err != nil {
-				return errtype.Join(ErrWriteBufLen, err)
+				return 0, 0, errtype.Join(ErrWriteBufLen, err)
 			}
+			cSize += length
 
 			crc ^= crc32.Checksum(compressedBuf[i].Bytes(), crct)
 
 			// Пишем сжатый блок
 			if wrote, err = compressedBuf[i].WriteTo(writeBuf); err != nil {
-				return errtype.Join(ErrWriteCompressBuf, err)
+				return 0, 0, errtype.Join(ErrWriteCompressBuf, err)
 			}
 			log.Println("В буфер записи записан блок размера:", wrote)
 			compressor[i].Reset(compressedBuf[i])
@@ -149,19 +336,17 @@ func compressFile(fi header.PathProvider, arcBuf io.Writer) error {
 
 	// Пишем признак конца файла
 	if err = filesystem.BinaryWrite(arcBuf, int64(-1)); err != nil {
-		return errtype.Join(ErrWriteEOF, err)
+		return 0, 0, errtype.Join(ErrWriteEOF, err)
 	}
 	log.Println("Записан EOF")
 
 	// Пишем контрольную сумму
 	if err = filesystem.BinaryWrite(arcBuf, crc); err != nil {
-		return errtype.Join(ErrWriteCRC, err)
+		return 0, 0, errtype.Join(ErrWriteCRC, err)
 	}
 	log.Printf("Записан CRC: %X\n", crc)
 
-	fmt.Println(fi.PathInArc())
-
-	return nil
+	return cSize, crc, nil
 }
 
 // Загружает данные в буферы несжатых данных
@@ -222,3 +407,15 @@ func compressBuffers() error {
 	}
 	return nil
 }
+
+// Писатель, подсчитывающий количество записанных байт
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countWriter) Write(p []byte) (n int, err error) {
+	n, err = cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
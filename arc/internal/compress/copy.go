@@ -0,0 +1,313 @@
+package compress
+
+import (
+	"archiver/arc/internal/decompress"
+	"archiver/arc/internal/generic"
+	"archiver/arc/internal/header"
+	c "archiver/compressor"
+	"archiver/errtype"
+	"bufio"
+	"bytes"
+	"cmp"
+	"io"
+	"log"
+	"slices"
+)
+
+// Запись архива, переносимая в другой архив без распаковки
+type Record struct {
+	header.IndexEntry       // Смещение записи в исходном архиве и ее заголовок
+	End               int64 // Смещение конца записи в исходном архиве
+}
+
+// Возвращает записи entries в порядке их следования
+// в архиве вместе с их границами. end -- смещение
+// конца записей архива.
+func Records(entries []header.IndexEntry, end int64) []Record {
+	records := make([]Record, len(entries))
+	for i, e := range entries {
+		records[i] = Record{IndexEntry: e, End: end}
+	}
+
+	slices.SortStableFunc(records, func(a, b Record) int {
+		return cmp.Compare(a.Offset, b.Offset)
+	})
+	for i := 1; i < len(records); i++ {
+		records[i-1].End = records[i].Offset
+	}
+
+	return records
+}
+
+// Ключ solid-группы исходного архива
+type groupKey struct {
+	src    io.ReadSeeker // Исходный архив
+	offset int64         // Смещение первого файла группы
+}
+
+// Solid-группа исходного архива, часть файлов которой
+// не переносится. Оставшиеся файлы сжимаются в новую
+// группу при переносе первого из них.
+type shrunkGroup struct {
+	members []*header.FileItem // Оставшиеся файлы в порядке следования
+	refs    map[*header.FileItem]header.SolidRef
+}
+
+// Переносит записи в новый архив без распаковки данных
+type Copier struct {
+	buf     *bufio.Writer
+	arcBuf  *countWriter
+	entries []header.IndexEntry
+	groups  map[groupKey]int64 // Новые смещения solid-групп
+	shrunk  map[groupKey]*shrunkGroup
+}
+
+// Создает [Copier], пишущий записи в arcOut
+// начиная со смещения offset
+func NewCopier(arcOut io.Writer, offset int64) *Copier {
+	buf := bufio.NewWriter(arcOut)
+
+	return &Copier{
+		buf:    buf,
+		arcBuf: &countWriter{w: buf, n: offset},
+		groups: map[groupKey]int64{},
+		shrunk: map[groupKey]*shrunkGroup{},
+	}
+}
+
+// Отмечает solid-группы arcFile, файлы которых переносятся
+// не все: all -- все записи архива, kept -- переносимые.
+// Оставшиеся файлы такой группы сжимаются заново общим
+// потоком, первый из них становится первым файлом группы.
+func (cp *Copier) Regroup(arcFile io.ReadSeeker, all, kept []Record) {
+	var (
+		counts  = map[int64]int{}
+		members = map[int64][]*header.FileItem{}
+	)
+
+	for _, r := range all {
+		if fi, ok := r.Header.(*header.FileItem); ok && fi.Solid().Kind != header.SolidNone {
+			counts[fi.Solid().Group]++
+		}
+	}
+
+	for _, r := range kept {
+		if fi, ok := r.Header.(*header.FileItem); ok && fi.Solid().Kind != header.SolidNone {
+			members[fi.Solid().Group] = append(members[fi.Solid().Group], fi)
+		}
+	}
+
+	for group, files := range members {
+		if len(files) < counts[group] {
+			cp.shrunk[groupKey{arcFile, group}] = &shrunkGroup{members: files}
+		}
+	}
+}
+
+// Переносит запись r из arcFile. Заголовок пишется заново,
+// так как путь элемента мог измениться, а сжатые данные
+// файла копируются без распаковки. Члены solid-группы
+// ссылаются на новое смещение первого файла группы,
+// который должен быть перенесен раньше них.
+func (cp *Copier) Copy(arcFile io.ReadSeeker, r Record) error {
+	return cp.copy(arcFile, r, cp.copyData)
+}
+
+// Переносит запись r из arcFile, сжимая данные файла заново
+// компрессором ct с уровнем cl. Данные распаковываются
+// блоками и сразу сжимаются, контрольная сумма
+// вычисляется заново.
+func (cp *Copier) Transcode(arcFile io.ReadSeeker, r Record, ct c.Type, cl c.Level) error {
+	fi, ok := r.Header.(*header.FileItem)
+	if !ok {
+		return cp.Copy(arcFile, r)
+	}
+
+	src := fi.CompType() // Члены solid-группы сжаты компрессором группы
+	fi.SetCodec(ct, cl)
+
+	return cp.copy(arcFile, r, func(arcFile io.ReadSeeker, r Record, fi *header.FileItem) error {
+		return cp.transcodeData(arcFile, r, fi, src)
+	})
+}
+
+// Переносит данные файла fi записи r из arcFile
+type dataFunc func(arcFile io.ReadSeeker, r Record, fi *header.FileItem) error
+
+// Переносит запись r, данные файла переносятся функцией data
+func (cp *Copier) copy(arcFile io.ReadSeeker, r Record, data dataFunc) (err error) {
+	offset := cp.arcBuf.n
+
+	fi, ok := r.Header.(*header.FileItem)
+	if !ok {
+		w, ok := r.Header.(interface{ Write(io.Writer) error })
+		if !ok {
+			return nil
+		}
+		if err = w.Write(cp.arcBuf); err != nil {
+			return errtype.Join(ErrCopyRecord, err)
+		}
+
+		cp.entries = append(cp.entries, header.IndexEntry{Offset: offset, Header: r.Header})
+		return nil
+	}
+
+	ref := fi.Solid()
+	if g, ok := cp.shrunk[groupKey{arcFile, ref.Group}]; ok && ref.Kind != header.SolidNone {
+		return cp.copyShrunk(arcFile, fi, g, offset)
+	}
+
+	switch ref.Kind {
+	case header.SolidHead:
+		cp.groups[groupKey{arcFile, r.Offset}] = offset
+		ref.Group = offset
+	case header.SolidMember:
+		if ref.Group, ok = cp.groups[groupKey{arcFile, ref.Group}]; !ok {
+			return ErrCopySolid(fi.PathInArc())
+		}
+	}
+	fi.SetSolid(ref)
+
+	if err = fi.Write(cp.arcBuf); err != nil {
+		return errtype.Join(ErrCopyRecord, err)
+	}
+
+	if fi.HasData() {
+		if err = data(arcFile, r, fi); err != nil {
+			return errtype.Join(ErrCopyRecord, err)
+		}
+	}
+	log.Println("Перенесена запись", fi.PathInArc(), "на смещение", offset)
+
+	cp.entries = append(cp.entries, header.IndexEntry{Offset: offset, Header: fi})
+	return nil
+}
+
+// Переносит файл fi из уменьшившейся solid-группы g на
+// смещение offset. Первый оставшийся файл группы пишется
+// вместе с новым потоком группы, остальные ссылаются на него.
+func (cp *Copier) copyShrunk(arcFile io.ReadSeeker, fi *header.FileItem, g *shrunkGroup, offset int64) (err error) {
+	if g.refs == nil {
+		err = cp.writeGroup(arcFile, fi, g, offset)
+	} else {
+		fi.SetSolid(g.refs[fi])
+		err = fi.Write(cp.arcBuf)
+	}
+	if err != nil {
+		return errtype.Join(ErrCopyRecord, err)
+	}
+	log.Println("Перенесена запись", fi.PathInArc(), "на смещение", offset)
+
+	cp.entries = append(cp.entries, header.IndexEntry{Offset: offset, Header: fi})
+	return nil
+}
+
+// Распаковывает поток solid-группы g из arcFile, собирает из
+// него данные оставшихся файлов и пишет первый из них, head,
+// с новым потоком. Единственный оставшийся файл пишется
+// как обычный файл.
+func (cp *Copier) writeGroup(arcFile io.ReadSeeker, head *header.FileItem, g *shrunkGroup, offset int64) error {
+	if _, err := decompress.DataOffset(arcFile, head.Solid().Group); err != nil {
+		return err
+	}
+
+	in := decompress.NewDataReader(arcFile, head.CompType())
+	stream, err := io.ReadAll(in)
+	if err != nil {
+		return err
+	} else if in.Damaged() {
+		return ErrTranscodeDamaged(head.PathInArc())
+	}
+
+	var (
+		data []byte
+		kind = header.SolidHead
+	)
+
+	g.refs = make(map[*header.FileItem]header.SolidRef, len(g.members))
+	for _, fi := range g.members {
+		start := fi.Solid().Offset
+		end := start + int64(fi.UcSize())
+		if start < 0 || end > int64(len(stream)) {
+			return ErrSolidOverflow
+		}
+
+		g.refs[fi] = header.SolidRef{Kind: kind, Group: offset, Offset: int64(len(data))}
+		data = append(data, stream[start:end]...)
+		kind = header.SolidMember
+	}
+
+	if len(g.members) == 1 {
+		g.refs[head] = header.SolidRef{}
+	}
+	head.SetSolid(g.refs[head])
+
+	if err = generic.InitCompressors(head.CompType(), head.CompLevel()); err != nil {
+		return errtype.Join(ErrCompressorInit, err)
+	}
+
+	if err = head.Write(cp.arcBuf); err != nil {
+		return err
+	}
+
+	cSize, crc, err := compressData(bytes.NewReader(data), cp.arcBuf)
+	if err != nil {
+		return err
+	}
+	head.SetCSize(header.Size(cSize))
+	head.SetCRC(crc)
+
+	return nil
+}
+
+// Копирует сжатые данные файла без распаковки
+func (cp *Copier) copyData(arcFile io.ReadSeeker, r Record, _ *header.FileItem) error {
+	data, err := decompress.DataOffset(arcFile, r.Offset)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.CopyN(cp.arcBuf, arcFile, r.End-data)
+	return err
+}
+
+// Распаковывает данные файла fi, сжатые компрессором src,
+// и сжимает их компрессором из заголовка файла
+func (cp *Copier) transcodeData(arcFile io.ReadSeeker, r Record, fi *header.FileItem, src c.Type) error {
+	if _, err := decompress.DataOffset(arcFile, r.Offset); err != nil {
+		return err
+	}
+
+	if err := generic.InitCompressors(fi.CompType(), fi.CompLevel()); err != nil {
+		return errtype.Join(ErrCompressorInit, err)
+	}
+
+	in := decompress.NewDataReader(arcFile, src)
+	cSize, crc, err := compressData(in, cp.arcBuf)
+	if err != nil {
+		return err
+	} else if in.Damaged() { // Повреждение не должно скрываться новой суммой
+		return ErrTranscodeDamaged(fi.PathInArc())
+	}
+
+	fi.SetCSize(header.Size(cSize))
+	fi.SetCRC(crc)
+
+	return nil
+}
+
+// Завершает перенос записей. Если index установлен,
+// то пишет индекс архива и завершающий блок.
+func (cp *Copier) Finish(index bool) error {
+	if index {
+		indexOffset := cp.arcBuf.n
+		if err := header.WriteIndex(cp.arcBuf, cp.entries); err != nil {
+			return errtype.Join(ErrWriteIndex, err)
+		}
+		if err := header.WriteFooter(cp.arcBuf, indexOffset); err != nil {
+			return errtype.Join(ErrWriteIndex, err)
+		}
+	}
+
+	return cp.buf.Flush()
+}
@@ -0,0 +1,41 @@
+package compress
+
+import (
+	"archiver/arc/internal/header"
+	c "archiver/compressor"
+	"io"
+	"os"
+)
+
+// Размер выборки из начала каждого файла для словаря
+const dictSample = 1 << 10
+
+// Строит предустановленный словарь из начал входных
+// файлов, пока не будет достигнут c.MaxDictSize.
+// Непрочитанные файлы в выборку не попадают.
+func BuildDict(headers []header.Header) (dict []byte) {
+	buf := make([]byte, dictSample)
+
+	for _, h := range headers {
+		fi, ok := h.(*header.FileItem)
+		if !ok || fi.UcSize() == 0 {
+			continue
+		}
+
+		n := min(dictSample, c.MaxDictSize-len(dict))
+		if n == 0 {
+			break
+		}
+
+		inFile, err := os.Open(fi.PathOnDisk())
+		if err != nil {
+			continue
+		}
+		n, _ = io.ReadFull(inFile, buf[:n])
+		inFile.Close()
+
+		dict = append(dict, buf[:n]...)
+	}
+
+	return dict
+}
@@ -4,21 +4,30 @@ import "archiver/arc/internal/errors"
 
 // Ошибки при сжатии
 var (
-	ErrNoEntries         = errors.ErrNoEntries
-	ErrWriteFileHeader   = errors.ErrWriteFileHeader
-	ErrCompressFile      = errors.ErrCompressFile
-	ErrReadUncompressed  = errors.ErrReadUncompressed
-	ErrCompress          = errors.ErrCompress
-	ErrWriteBufLen       = errors.ErrWriteBufLen
-	ErrWriteCompressBuf  = errors.ErrWriteCompressBuf
-	ErrReadUncompressBuf = errors.ErrReadUncompressBuf
-	ErrWriteEOF          = errors.ErrWriteEOF
-	ErrWriteCRC          = errors.ErrWriteCRC
-	ErrWriteCompressor   = errors.ErrWriteCompressor
-	ErrCloseCompressor   = errors.ErrCloseCompressor
-	ErrFetchDirs         = errors.ErrFetchDirs
+	ErrNoEntries          = errors.ErrNoEntries
+	ErrCompressorInit     = errors.ErrCompressorInit
+	ErrWriteFileHeader    = errors.ErrWriteFileHeader
+	ErrWriteDirHeader     = errors.ErrWriteDirHeader
+	ErrWriteLinkHeader    = errors.ErrWriteLinkHeader
+	ErrWriteSpecialHeader = errors.ErrWriteSpecialHeader
+	ErrCompressFile       = errors.ErrCompressFile
+	ErrReadUncompressed   = errors.ErrReadUncompressed
+	ErrCompress           = errors.ErrCompress
+	ErrWriteBufLen        = errors.ErrWriteBufLen
+	ErrWriteCompressBuf   = errors.ErrWriteCompressBuf
+	ErrReadUncompressBuf  = errors.ErrReadUncompressBuf
+	ErrWriteEOF           = errors.ErrWriteEOF
+	ErrWriteCRC           = errors.ErrWriteCRC
+	ErrWriteCompressor    = errors.ErrWriteCompressor
+	ErrCloseCompressor    = errors.ErrCloseCompressor
+	ErrFetchDirs          = errors.ErrFetchDirs
+	ErrWriteIndex         = errors.ErrWriteIndex
+	ErrCopyRecord         = errors.ErrCopyRecord
 
 	ErrLongPath = errors.ErrLongPath
 
 	ErrOpenFileCompress = errors.ErrOpenFileCompress
+	ErrCopySolid        = errors.ErrCopySolid
+	ErrTranscodeDamaged = errors.ErrTranscodeDamaged
+	ErrSolidOverflow    = errors.ErrSolidOverflow
 )
@@ -2,9 +2,85 @@ package platform
 
 import (
 	"os"
+	"os/user"
+	"strconv"
 	"time"
 )
 
 func Timestamp(info os.FileInfo) (atime time.Time, mtime time.Time) {
 	return amTimes(info)
 }
+
+// Возвращает время изменения метаданных и время создания,
+// если платформа их предоставляет, иначе нулевое время
+func ChangeBirthTimes(info os.FileInfo) (ctime time.Time, btime time.Time) {
+	return cbTimes(info)
+}
+
+// Идентификатор файла в файловой системе
+type FileId struct {
+	Dev uint64 // Устройство
+	Ino uint64 // Индексный дескриптор
+}
+
+// Возвращает идентификатор файла. Если у файла
+// одна жесткая ссылка, то ok равен false.
+func HardLinkId(info os.FileInfo) (id FileId, ok bool) {
+	id, nlink, ok := fileId(info)
+	return id, ok && nlink > 1
+}
+
+// Возвращает старший и младший номера устройства.
+// Если платформа их не предоставляет, то ok равен false.
+func DeviceNumbers(info os.FileInfo) (major, minor uint32, ok bool) {
+	return devNumbers(info)
+}
+
+// Возвращает расширенные атрибуты файла path или
+// nil, если их нет или платформа их не поддерживает.
+// Символьные ссылки разыменовываются.
+func Xattrs(path string) map[string][]byte {
+	return xattrs(path)
+}
+
+// Область данных разреженного файла
+type Region struct {
+	Offset int64 // Смещение области от начала файла
+	Length int64 // Длина области
+}
+
+// Возвращает области данных файла f, если он разреженный,
+// иначе ok равен false. Позиция в файле сбрасывается в начало.
+func SparseRegions(f *os.File) (regions []Region, ok bool) {
+	return sparseRegions(f)
+}
+
+// Кэш имен пользователей и групп по их идентификаторам
+var (
+	userNames  = map[uint32]string{}
+	groupNames = map[uint32]string{}
+)
+
+// Возвращает идентификаторы и имена владельца и группы
+func Owner(info os.FileInfo) (uid, gid uint32, uname, gname string) {
+	var ok bool
+	if uid, gid, ok = ownerIds(info); !ok {
+		return 0, 0, "", ""
+	}
+
+	if uname, ok = userNames[uid]; !ok {
+		if u, err := user.LookupId(strconv.FormatUint(uint64(uid), 10)); err == nil {
+			uname = u.Username
+		}
+		userNames[uid] = uname
+	}
+
+	if gname, ok = groupNames[gid]; !ok {
+		if g, err := user.LookupGroupId(strconv.FormatUint(uint64(gid), 10)); err == nil {
+			gname = g.Name
+		}
+		groupNames[gid] = gname
+	}
+
+	return uid, gid, uname, gname
+}
@@ -17,3 +17,30 @@ func amTimes(info os.FileInfo) (atime time.Time, mtime time.Time) {
 
 	return atime, mtime
 }
+
+// Возвращает время изменения метаданных и время создания
+func cbTimes(info os.FileInfo) (ctime time.Time, btime time.Time) {
+	stat := info.Sys().(*syscall.Stat_t)
+	ctime = time.Unix(stat.Ctimespec.Sec, stat.Ctimespec.Nsec)
+	btime = time.Unix(stat.Birthtimespec.Sec, stat.Birthtimespec.Nsec)
+
+	return ctime, btime
+}
+
+// Возвращает идентификатор файла и количество жестких ссылок
+func fileId(info os.FileInfo) (id FileId, nlink uint64, ok bool) {
+	stat := info.Sys().(*syscall.Stat_t)
+	return FileId{uint64(stat.Dev), stat.Ino}, uint64(stat.Nlink), true
+}
+
+// Возвращает номера устройства
+func devNumbers(info os.FileInfo) (major, minor uint32, ok bool) {
+	dev := uint32(info.Sys().(*syscall.Stat_t).Rdev)
+	return dev >> 24 & 0xff, dev & 0xffffff, true
+}
+
+// Возвращает идентификаторы владельца и группы
+func ownerIds(info os.FileInfo) (uid, gid uint32, ok bool) {
+	stat := info.Sys().(*syscall.Stat_t)
+	return stat.Uid, stat.Gid, true
+}
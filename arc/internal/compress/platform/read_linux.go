@@ -17,3 +17,33 @@ func amTimes(info os.FileInfo) (atime time.Time, mtime time.Time) {
 
 	return atime, mtime
 }
+
+// Возвращает время изменения метаданных. Время создания
+// доступно только через statx, которого нет в [syscall].
+func cbTimes(info os.FileInfo) (ctime time.Time, btime time.Time) {
+	stat := info.Sys().(*syscall.Stat_t)
+	ctime = time.Unix(int64(stat.Ctim.Sec), int64(stat.Ctim.Nsec))
+
+	return ctime, time.Time{}
+}
+
+// Возвращает идентификатор файла и количество жестких ссылок
+func fileId(info os.FileInfo) (id FileId, nlink uint64, ok bool) {
+	stat := info.Sys().(*syscall.Stat_t)
+	return FileId{uint64(stat.Dev), uint64(stat.Ino)}, uint64(stat.Nlink), true
+}
+
+// Возвращает номера устройства в кодировке glibc
+func devNumbers(info os.FileInfo) (major, minor uint32, ok bool) {
+	dev := uint64(info.Sys().(*syscall.Stat_t).Rdev)
+	major = uint32((dev>>8)&0xfff | (dev>>32)&^0xfff)
+	minor = uint32(dev&0xff | (dev>>12)&^0xff)
+
+	return major, minor, true
+}
+
+// Возвращает идентификаторы владельца и группы
+func ownerIds(info os.FileInfo) (uid, gid uint32, ok bool) {
+	stat := info.Sys().(*syscall.Stat_t)
+	return stat.Uid, stat.Gid, true
+}
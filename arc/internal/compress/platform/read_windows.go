@@ -17,3 +17,29 @@ func amTimes(info os.FileInfo) (atime time.Time, mtime time.Time) {
 
 	return atime, mtime
 }
+
+// Возвращает время создания. Времени изменения
+// метаданных в Windows нет.
+func cbTimes(info os.FileInfo) (ctime time.Time, btime time.Time) {
+	stat := info.Sys().(*syscall.Win32FileAttributeData)
+	btime = time.Unix(0, stat.CreationTime.Nanoseconds())
+
+	return time.Time{}, btime
+}
+
+// Индексы файлов недоступны через [os.FileInfo],
+// жесткие ссылки не определяются
+func fileId(os.FileInfo) (id FileId, nlink uint64, ok bool) {
+	return FileId{}, 0, false
+}
+
+// Узлов устройств в файловой системе нет
+func devNumbers(os.FileInfo) (major, minor uint32, ok bool) {
+	return 0, 0, false
+}
+
+// Владелец задается дескриптором безопасности,
+// числовых идентификаторов нет
+func ownerIds(os.FileInfo) (uid, gid uint32, ok bool) {
+	return 0, 0, false
+}
@@ -0,0 +1,59 @@
+//go:build linux
+// +build linux
+
+package platform
+
+import (
+	"errors"
+	"io"
+	"os"
+	"syscall"
+)
+
+// Значения whence для поиска данных и дыр в файле
+const (
+	seekData = 3 // SEEK_DATA
+	seekHole = 4 // SEEK_HOLE
+)
+
+// Находит области данных через SEEK_DATA и SEEK_HOLE.
+// Файлы, занимающие на диске не меньше своего размера,
+// не проверяются.
+func sparseRegions(f *os.File) (regions []Region, ok bool) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, false
+	}
+	defer f.Seek(0, io.SeekStart)
+
+	size := info.Size()
+	stat := info.Sys().(*syscall.Stat_t)
+	if size == 0 || stat.Blocks*512 >= size {
+		return nil, false
+	}
+
+	for offset := int64(0); offset < size; {
+		data, err := f.Seek(offset, seekData)
+		if errors.Is(err, syscall.ENXIO) {
+			break // Дальше до конца файла дыра
+		} else if err != nil {
+			return nil, false
+		}
+
+		hole, err := f.Seek(data, seekHole)
+		if err != nil {
+			return nil, false
+		}
+
+		regions = append(regions, Region{data, hole - data})
+		offset = hole
+	}
+
+	if len(regions) == 0 { // Файл из одних дыр
+		regions = append(regions, Region{size, 0})
+	} else if len(regions) == 1 && regions[0].Length == size {
+		return nil, false // Дыр нет, файл занимает меньше из-за сжатия ФС
+	}
+
+	return regions, true
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+// +build !linux
+
+package platform
+
+import "os"
+
+// Разреженные файлы определяются только в Linux
+func sparseRegions(*os.File) (regions []Region, ok bool) {
+	return nil, false
+}
@@ -0,0 +1,52 @@
+//go:build linux
+// +build linux
+
+package platform
+
+import (
+	"bytes"
+
+	"golang.org/x/sys/unix"
+)
+
+// Возвращает расширенные атрибуты файла path. Атрибуты
+// символической ссылки читаются у самой ссылки.
+func xattrs(path string) map[string][]byte {
+	size, err := unix.Llistxattr(path, nil)
+	if err != nil || size == 0 {
+		return nil
+	}
+
+	list := make([]byte, size)
+	if size, err = unix.Llistxattr(path, list); err != nil {
+		return nil
+	}
+
+	attrs := map[string][]byte{}
+	for _, name := range bytes.Split(list[:size], []byte{0}) {
+		if len(name) == 0 {
+			continue
+		}
+
+		if value, ok := getxattr(path, string(name)); ok {
+			attrs[string(name)] = value
+		}
+	}
+
+	return attrs
+}
+
+// Возвращает значение расширенного атрибута name файла path
+func getxattr(path, name string) ([]byte, bool) {
+	size, err := unix.Lgetxattr(path, name, nil)
+	if err != nil {
+		return nil, false
+	}
+
+	value := make([]byte, size)
+	if size, err = unix.Lgetxattr(path, name, value); err != nil {
+		return nil, false
+	}
+
+	return value[:size], true
+}
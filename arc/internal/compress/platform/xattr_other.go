@@ -0,0 +1,10 @@
+//go:build !linux
+// +build !linux
+
+package platform
+
+// Расширенные атрибуты не читаются, в пакете
+// syscall этой платформы нет системных вызовов для них
+func xattrs(string) map[string][]byte {
+	return nil
+}
@@ -17,8 +17,8 @@ import (
 // интерфейс заголовка, указывающий на
 // соответствующий тип
 func fetchPath(path string) (h header.Header, err error) {
-	if len(path) > 1023 {
-		return nil, ErrLongPath(path)
+	if len(path) > header.MaxPathLen {
+		return nil, ErrLongPath(path, header.MaxPathLen)
 	}
 
 	info, err := os.Lstat(path)
@@ -26,11 +26,16 @@ func fetchPath(path string) (h header.Header, err error) {
 		return nil, err
 	}
 	atime, mtime := platform.Timestamp(info)
+	uid, gid, uname, gname := platform.Owner(info)
 
 	b, err := header.NewBase(fp.ToSlash(path), atime, mtime)
 	if err != nil {
 		return nil, err
 	}
+	b.SetChangeBirthTimes(platform.ChangeBirthTimes(info))
+	b.SetMode(info.Mode())
+	b.SetOwner(uid, gid, uname, gname)
+	b.SetXattrs(platform.Xattrs(path))
 
 	if info.Mode()&os.ModeSymlink != 0 {
 		target, err := fp.EvalSymlinks(path)
@@ -44,17 +49,69 @@ func fetchPath(path string) (h header.Header, err error) {
 		if target, err = fp.Abs(target); err != nil {
 			return nil, err
 		} else {
-			h = header.NewSymItem(path, target)
+			si := header.NewSymItem(path, target)
+			si.SetMode(info.Mode())
+			si.SetOwner(uid, gid, uname, gname)
+			si.SetXattrs(b.Xattrs())
+			h = si
 		}
 	} else if info.Mode()&os.ModeDir != 0 {
-		h = header.NewDirItem(b.PathInArc())
+		h = header.NewDirItem(b)
+	} else if info.Mode()&os.ModeNamedPipe != 0 {
+		h = header.NewFifoItem(b)
+	} else if info.Mode()&os.ModeDevice != 0 {
+		major, minor, ok := platform.DeviceNumbers(info)
+		if !ok {
+			fmt.Printf("Пропускаю устройство '%s'\n", path)
+			return nil, nil
+		}
+		char := info.Mode()&os.ModeCharDevice != 0
+		h = header.NewDeviceItem(b, char, major, minor)
+	} else if info.Mode()&os.ModeSocket != 0 {
+		fmt.Printf("Пропускаю сокет '%s'\n", path)
+		return nil, nil
 	} else {
-		h = header.NewFileItem(b, header.Size(info.Size()))
+		fi := header.NewFileItem(b, header.Size(info.Size()))
+		if id, ok := platform.HardLinkId(info); ok {
+			fileIds[fi] = id
+		}
+		h = fi
 	}
 
 	return h, nil
 }
 
+// Идентификаторы файлов с несколькими жесткими ссылками
+var fileIds = map[*header.FileItem]platform.FileId{}
+
+// Заменяет заголовки файлов, уже встречавшихся в
+// отсортированном срезе headers под другим путем,
+// на заголовки жестких ссылок на первый путь
+func linkHardlinks(headers []header.Header) []header.Header {
+	defer clear(fileIds)
+
+	first := map[platform.FileId]string{}
+	for i, h := range headers {
+		fi, ok := h.(*header.FileItem)
+		if !ok {
+			continue
+		}
+
+		id, ok := fileIds[fi]
+		if !ok {
+			continue
+		}
+
+		if target, ok := first[id]; ok {
+			headers[i] = header.NewLinkItem(fi.PathInArc(), target)
+		} else {
+			first[id] = fi.PathInArc()
+		}
+	}
+
+	return headers
+}
+
 // Рекурсивно собирает элементы в директории
 func fetchDir(path string) (headers []header.Header, err error) {
 	err = fp.WalkDir(path, func(path string, _ os.DirEntry, err error) error {
@@ -62,14 +119,14 @@ func fetchDir(path string) (headers []header.Header, err error) {
 			return err
 		}
 
+		if len(path) > header.MaxPathLen {
+			fmt.Println(ErrLongPath(path, header.MaxPathLen))
+			return nil
+		}
+
 		header, err := fetchPath(path)
 		if err != nil {
-			if err == ErrLongPath(path) {
-				fmt.Println(err)
-				return nil
-			} else {
-				return err
-			}
+			return err
 		}
 
 		if header != nil {
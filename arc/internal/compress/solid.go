@@ -0,0 +1,154 @@
+package compress
+
+import (
+	"archiver/arc/internal/generic"
+	"archiver/arc/internal/header"
+	c "archiver/compressor"
+	"archiver/errtype"
+	"bytes"
+	"fmt"
+	"os"
+)
+
+const (
+	// Максимальный размер файла, сжимаемого в solid-группе
+	solidFileSize header.Size = 64 << 10
+	// Ограничение размера распакованного потока группы,
+	// поток распаковывается в память целиком. Размер не
+	// зависит от машины, чтобы архив одних и тех же файлов
+	// делился на группы одинаково.
+	solidGroupSize int64 = 4 << 20
+)
+
+// Группа мелких файлов, сжимаемых общим потоком.
+// Запись первого файла группы сопровождается данными
+// всей группы, записи остальных файлов хранят смещение
+// своих данных в распакованном потоке.
+type solidGroup struct {
+	members int     // Количество файлов в группе
+	size    int64   // Суммарный размер данных файлов
+	ct      c.Type  // Компрессор группы
+	cl      c.Level // Уровень сжатия группы
+	// Файлы группы и элементы между ними в исходном порядке
+	pending []header.Header
+}
+
+// Сообщает, может ли элемент h войти в группу
+func (g solidGroup) fits(h header.Header) bool {
+	fi, ok := h.(*header.FileItem)
+	if !ok || fi.UcSize() == 0 || fi.UcSize() > solidFileSize ||
+		len(fi.Sparse()) > 0 || fi.CompType() == c.Nop {
+		return false
+	}
+
+	if g.members == 0 {
+		return true
+	}
+
+	return fi.CompType() == g.ct && fi.CompLevel() == g.cl &&
+		g.size+int64(fi.UcSize()) <= solidGroupSize
+}
+
+// Добавляет файл fi в группу
+func (g *solidGroup) add(fi *header.FileItem) {
+	if g.members == 0 {
+		g.ct, g.cl = fi.CompType(), fi.CompLevel()
+	}
+
+	g.members++
+	g.size += int64(fi.UcSize())
+	g.pending = append(g.pending, fi)
+}
+
+// Пишет группу и отложенные элементы в arcBuf,
+// добавляет их в индекс entries и очищает группу.
+// Группа из одного файла пишется как обычный файл.
+func (g *solidGroup) flush(arcBuf *countWriter, entries *[]header.IndexEntry) (err error) {
+	defer func() { *g = solidGroup{} }()
+
+	if g.members < 2 {
+		for _, h := range g.pending {
+			if err = writeHeader(h, arcBuf, entries); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var (
+		head   = g.pending[0].(*header.FileItem)
+		offset = arcBuf.n
+		data   []byte
+	)
+
+	if data, err = g.load(offset); err != nil {
+		return errtype.Join(ErrCompressFile, err)
+	}
+
+	if err = generic.InitCompressors(g.ct, g.cl); err != nil {
+		return errtype.Join(ErrCompressorInit, err)
+	}
+
+	if err = head.Write(arcBuf); err != nil {
+		return errtype.Join(ErrWriteFileHeader, err)
+	}
+
+	cSize, crc, err := compressData(bytes.NewReader(data), arcBuf)
+	if err != nil {
+		return errtype.Join(ErrCompressFile, err)
+	}
+	head.SetCSize(header.Size(cSize))
+	head.SetCRC(crc)
+
+	*entries = append(*entries, header.IndexEntry{Offset: offset, Header: head})
+	fmt.Println(head.PathInArc())
+
+	for _, h := range g.pending[1:] {
+		fi, ok := h.(*header.FileItem) // Файлы вне группы в нее не попадают
+		if !ok {
+			if err = writeHeader(h, arcBuf, entries); err != nil {
+				return err
+			}
+			continue
+		}
+
+		*entries = append(*entries, header.IndexEntry{Offset: arcBuf.n, Header: fi})
+		if err = fi.Write(arcBuf); err != nil {
+			return errtype.Join(ErrWriteFileHeader, err)
+		}
+		fmt.Println(fi.PathInArc())
+	}
+
+	return nil
+}
+
+// Читает данные файлов группы в общий поток и
+// устанавливает их положение в нем. group --
+// смещение записи первого файла группы.
+func (g solidGroup) load(group int64) ([]byte, error) {
+	var (
+		stream = make([]byte, 0, g.size)
+		kind   = header.SolidHead
+	)
+
+	for _, h := range g.pending {
+		fi, ok := h.(*header.FileItem)
+		if !ok {
+			continue
+		}
+
+		data, err := os.ReadFile(fi.PathOnDisk())
+		if err != nil {
+			return nil, errtype.Join(ErrOpenFileCompress(fi.PathOnDisk()), err)
+		}
+
+		fi.SetUcSize(header.Size(len(data))) // Файл мог измениться
+		fi.SetSolid(header.SolidRef{
+			Kind: kind, Group: group, Offset: int64(len(stream)),
+		})
+		stream = append(stream, data...)
+		kind = header.SolidMember
+	}
+
+	return stream, nil
+}
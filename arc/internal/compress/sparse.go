@@ -0,0 +1,44 @@
+package compress
+
+import (
+	"archiver/arc/internal/compress/platform"
+	"archiver/arc/internal/header"
+	"archiver/errtype"
+	"io"
+	"os"
+)
+
+// Сохраняет в заголовке карту областей данных,
+// если файл разреженный
+func detectSparse(fi *header.FileItem) error {
+	inFile, err := os.Open(fi.PathOnDisk())
+	if err != nil {
+		return errtype.Join(ErrOpenFileCompress(fi.PathOnDisk()), err)
+	}
+	defer inFile.Close()
+
+	regions, ok := platform.SparseRegions(inFile)
+	if !ok {
+		fi.SetSparse(nil)
+		return nil
+	}
+
+	sparse := make([]header.SparseRegion, len(regions))
+	for i, r := range regions {
+		sparse[i] = header.SparseRegion{Offset: r.Offset, Length: r.Length}
+	}
+	fi.SetSparse(sparse)
+
+	return nil
+}
+
+// Возвращает читателя, последовательно
+// читающего области данных файла f
+func sparseReader(f *os.File, regions []header.SparseRegion) io.Reader {
+	readers := make([]io.Reader, len(regions))
+	for i, r := range regions {
+		readers[i] = io.NewSectionReader(f, r.Offset, r.Length)
+	}
+
+	return io.MultiReader(readers...)
+}
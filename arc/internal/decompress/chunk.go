@@ -0,0 +1,226 @@
+package decompress
+
+import (
+	"archiver/arc/internal/generic"
+	"archiver/arc/internal/header"
+	c "archiver/compressor"
+	"archiver/errtype"
+	"archiver/filesystem"
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"os"
+)
+
+// Положение фрагмента в данных хранящего его файла
+type chunkLoc struct {
+	data   int64  // Смещение сжатых данных файла от начала архива
+	offset int64  // Смещение фрагмента в распакованных данных файла
+	length uint32 // Длина фрагмента
+	ct     c.Type // Компрессор данных файла
+}
+
+var (
+	// Фрагменты архива по их хешу
+	chunkTable = map[header.ChunkHash]chunkLoc{}
+	// Последний распакованный блок сжатых данных
+	chunkBlock struct {
+		valid bool
+		data  int64 // Смещение данных файла
+		index int64 // Номер блока в данных файла
+		buf   []byte
+	}
+)
+
+// Строит таблицу фрагментов архива по записям файлов,
+// вызывается перед распаковкой и проверкой целостности.
+// Файлы ссылаются на фрагменты по хешу, поэтому порядок
+// записей в архиве не важен.
+func LoadChunks(arcFile io.ReadSeekCloser, arcLenH int64) error {
+	clear(chunkTable)
+	chunkBlock.valid, chunkBlock.buf = false, nil
+
+	if !header.HasFlag(header.FlagDedup) {
+		return nil
+	}
+
+	entries, err := ReadEntries(arcFile, arcLenH)
+	if err != nil {
+		return err
+	}
+	defer arcFile.Seek(arcLenH, io.SeekStart)
+
+	for _, e := range entries {
+		fi, ok := e.Header.(*header.FileItem)
+		if !ok || len(fi.Chunks()) == 0 {
+			continue
+		}
+
+		data, err := DataOffset(arcFile, e.Offset)
+		if err != nil {
+			return errtype.Join(ErrReadChunks, err)
+		}
+
+		var offset int64
+		for _, ch := range fi.Chunks() {
+			if !ch.Stored {
+				continue
+			}
+
+			if _, ok := chunkTable[ch.Hash]; !ok {
+				chunkTable[ch.Hash] = chunkLoc{
+					data: data, offset: offset,
+					length: ch.Length, ct: fi.CompType(),
+				}
+			}
+			offset += int64(ch.Length)
+		}
+	}
+
+	return nil
+}
+
+// Дописывает в outFile фрагменты файла fi, хранящиеся в
+// данных других файлов. Возвращает true, если какой-либо
+// фрагмент не удалось прочитать или его хеш не совпал,
+// на месте такого фрагмента в файле остаются нули.
+// Позиция в arcFile восстанавливается.
+func restoreChunks(fi *header.FileItem, arcFile io.ReadSeeker, outFile *os.File) (damaged bool, err error) {
+	pos, _ := arcFile.Seek(0, io.SeekCurrent)
+	defer arcFile.Seek(pos, io.SeekStart)
+
+	var offset int64
+	for _, ch := range fi.Chunks() {
+		if !ch.Stored {
+			data, err := readChunk(arcFile, ch)
+			if err != nil || sha256.Sum256(data) != ch.Hash {
+				damaged = true
+			} else if _, err = outFile.WriteAt(data, offset); err != nil {
+				return false, errtype.Join(ErrWriteOutBuf, err)
+			}
+		}
+		offset += int64(ch.Length)
+	}
+
+	// Фрагмент в конце файла мог не записаться
+	if err = outFile.Truncate(int64(fi.UcSize())); err != nil {
+		return false, errtype.Join(ErrWriteOutBuf, err)
+	}
+
+	return damaged, nil
+}
+
+// Проверяет фрагменты файла fi, хранящиеся в данных
+// других файлов. Возвращает true, если какой-либо
+// фрагмент не удалось прочитать или его хеш не
+// совпал. Позиция в arcFile восстанавливается.
+func CheckChunks(fi *header.FileItem, arcFile io.ReadSeeker) (damaged bool) {
+	pos, _ := arcFile.Seek(0, io.SeekCurrent)
+	defer arcFile.Seek(pos, io.SeekStart)
+
+	for _, ch := range fi.Chunks() {
+		if ch.Stored {
+			continue
+		}
+
+		if data, err := readChunk(arcFile, ch); err != nil ||
+			sha256.Sum256(data) != ch.Hash {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Читает фрагмент ch из данных хранящего его файла.
+// Распаковываются только блоки, содержащие фрагмент,
+// все блоки кроме последнего имеют размер буфера.
+func readChunk(arcFile io.ReadSeeker, ch header.Chunk) ([]byte, error) {
+	loc, ok := chunkTable[ch.Hash]
+	if !ok {
+		return nil, ErrChunkNotFound
+	} else if loc.length != ch.Length {
+		return nil, ErrChunkOverflow
+	}
+
+	var (
+		bufferSize = int64(generic.BufferSize())
+		start      = loc.offset
+		end        = loc.offset + int64(loc.length)
+		out        = make([]byte, 0, loc.length)
+		size       int64
+	)
+
+	if _, err := arcFile.Seek(loc.data, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	for i := int64(0); i*bufferSize < end; i++ {
+		if err := filesystem.BinaryRead(arcFile, &size); err != nil {
+			return nil, errtype.Join(ErrReadCompLen, err)
+		} else if size == -1 {
+			return nil, ErrChunkOverflow
+		} else if generic.CheckBufferSize(size) {
+			return nil, ErrBufSize(size)
+		}
+
+		if (i+1)*bufferSize <= start { // Блок до фрагмента
+			if _, err := arcFile.Seek(size, io.SeekCurrent); err != nil {
+				return nil, errtype.Join(ErrSkipData, err)
+			}
+			continue
+		}
+
+		block, err := loadChunkBlock(arcFile, loc, i, size)
+		if err != nil {
+			return nil, err
+		}
+
+		lo := max(start-i*bufferSize, 0)
+		hi := min(end-i*bufferSize, int64(len(block)))
+		if lo > hi {
+			return nil, ErrChunkOverflow
+		}
+		out = append(out, block[lo:hi]...)
+	}
+
+	if len(out) != int(loc.length) {
+		return nil, ErrChunkOverflow
+	}
+
+	return out, nil
+}
+
+// Распаковывает блок index размера size данных файла,
+// на начале которого стоит arcFile. Последний
+// распакованный блок не распаковывается повторно.
+func loadChunkBlock(arcFile io.ReadSeeker, loc chunkLoc, index, size int64) ([]byte, error) {
+	if chunkBlock.valid && chunkBlock.data == loc.data && chunkBlock.index == index {
+		if _, err := arcFile.Seek(size, io.SeekCurrent); err != nil {
+			return nil, errtype.Join(ErrSkipData, err)
+		}
+		return chunkBlock.buf, nil
+	}
+
+	compressed := make([]byte, size)
+	if _, err := io.ReadFull(arcFile, compressed); err != nil {
+		return nil, errtype.Join(ErrReadCompBuf, err)
+	}
+
+	decompressor, err := c.NewReaderDict(loc.ct, bytes.NewReader(compressed), generic.Dictionary())
+	if err != nil {
+		return nil, errtype.Join(ErrDecompInit, err)
+	}
+	defer decompressor.Close()
+
+	var buf bytes.Buffer
+	if _, err = buf.ReadFrom(decompressor); err != nil &&
+		err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, errtype.Join(ErrReadDecomp, err)
+	}
+
+	chunkBlock.valid, chunkBlock.data = true, loc.data
+	chunkBlock.index, chunkBlock.buf = index, buf.Bytes()
+
+	return chunkBlock.buf, nil
+}
@@ -13,6 +13,8 @@ import (
 	"log"
 	"os"
 	fp "path/filepath"
+	"slices"
+	"strings"
 	"sync"
 	"unicode"
 )
@@ -37,21 +39,38 @@ func RestoreFile(arcFile io.ReadSeeker, rp generic.RestoreParams) error {
 
 	outPath := fp.Join(rp.OutputDir, fi.PathOnDisk())
 	if _, err = os.Stat(outPath); err == nil && !rp.ReplaceAll {
-		if replaceInput(outPath, arcFile, &rp.ReplaceAll) {
+		if replaceInput(outPath, &rp.ReplaceAll) {
+			if fi.HasData() {
+				skipFileData(arcFile, true)
+			}
 			return nil
 		}
 	}
 
-	if rp.Integ { // --xinteg
+	if rp.Integ && fi.HasData() { // --xinteg
 		pos, _ := arcFile.Seek(0, io.SeekCurrent)
-		if _, err = CheckCRC(arcFile, rp.Ct); err == ErrWrongCRC {
+		if _, err = CheckCRC(arcFile, fi.CompType()); err == ErrWrongCRC {
+			if fi.Solid().Kind == header.SolidHead {
+				markSolidDamaged(fi)
+			}
+			fmt.Printf("Пропускаю поврежденный '%s'\n", fi.PathOnDisk())
+			return nil
+		} else if len(fi.Chunks()) > 0 && CheckChunks(fi, arcFile) {
 			fmt.Printf("Пропускаю поврежденный '%s'\n", fi.PathOnDisk())
 			return nil
 		}
 		arcFile.Seek(pos, io.SeekStart)
 	}
 
-	if err = decompressFile(fi, arcFile, outPath, rp.Ct); err != nil {
+	if fi.Solid().Kind != header.SolidNone {
+		skip, err := restoreSolid(fi, arcFile, outPath, rp.Integ)
+		if err != nil {
+			return err
+		} else if skip {
+			fmt.Printf("Пропускаю поврежденный '%s'\n", fi.PathOnDisk())
+			return nil
+		}
+	} else if err = decompressFile(fi, arcFile, outPath); err != nil {
 		return err
 	}
 
@@ -61,6 +80,22 @@ func RestoreFile(arcFile io.ReadSeeker, rp generic.RestoreParams) error {
 		fmt.Println(outPath)
 	}
 
+	if rp.RestoreOwner { // Смена владельца сбрасывает биты setuid и setgid
+		if err = fi.RestoreOwner(rp.OutputDir); err != nil {
+			fmt.Println(errtype.Join(ErrRestoreOwner(outPath), err))
+		}
+	}
+
+	if rp.RestoreXattrs { // После смены владельца, она сбрасывает capabilities
+		if err = fi.RestoreXattrs(rp.OutputDir); err != nil {
+			fmt.Println(errtype.Join(ErrRestoreXattrs(outPath), err))
+		}
+	}
+
+	if err = fi.RestoreMode(rp.OutputDir); err != nil {
+		return errtype.Join(ErrRestoreMode(outPath), err)
+	}
+
 	return fi.RestoreTime(rp.OutputDir)
 }
 
@@ -79,13 +114,154 @@ func RestoreSym(arcFile io.ReadSeeker, rp generic.RestoreParams) error {
 		)
 	}
 
+	if rp.RestoreOwner {
+		if err = sym.RestoreOwner(rp.OutputDir); err != nil {
+			fmt.Println(errtype.Join(ErrRestoreOwner(sym.PathInArc()), err))
+		}
+	}
+
+	if rp.RestoreXattrs {
+		if err = sym.RestoreXattrs(rp.OutputDir); err != nil {
+			fmt.Println(errtype.Join(ErrRestoreXattrs(sym.PathInArc()), err))
+		}
+	}
+
 	fmt.Println(sym.PathInArc(), "->", sym.PathOnDisk())
 
 	return nil
 }
 
-// Обрабатывает диалог замены файла
-func replaceInput(outPath string, arcFile io.ReadSeeker, replaceAll *bool) bool {
+// Восстанавливает жесткую ссылку на ранее распакованный файл
+func RestoreLink(arcFile io.ReadSeeker, rp generic.RestoreParams) error {
+	li := &header.LinkItem{}
+
+	if err := li.Read(arcFile); err != nil {
+		return errtype.Join(ErrReadLinkHeader, err)
+	}
+
+	outPath := fp.Join(rp.OutputDir, li.PathInArc())
+	if err := li.RestorePath(rp.OutputDir); err != nil {
+		return errtype.Join(ErrDecompressLink, err)
+	}
+
+	fmt.Println(outPath, "=>", fp.Join(rp.OutputDir, li.PathOnDisk()))
+
+	return nil
+}
+
+// Заголовок именованного канала или устройства
+type specialItem interface {
+	header.Header
+	Read(io.Reader) error
+	RestorePath(outDir string) error
+	RestoreOwner(outDir string) error
+	RestoreXattrs(outDir string) error
+	RestoreMode(outDir string) error
+	RestoreTime(outDir string) error
+}
+
+// Восстанавливает именованный канал или устройство, заголовок
+// которого читается в sp. Если создать элемент не удалось,
+// например, без прав на создание устройств, то выводит
+// предупреждение и продолжает распаковку.
+func RestoreSpecial(arcFile io.ReadSeeker, sp specialItem, rp generic.RestoreParams) error {
+	if err := sp.Read(arcFile); err != nil {
+		return errtype.Join(ErrReadSpecialHeader, err)
+	}
+
+	outPath := fp.Join(rp.OutputDir, sp.PathOnDisk())
+	if err := sp.RestorePath(rp.OutputDir); err != nil {
+		fmt.Println(errtype.Join(ErrRestoreSpecial(outPath), err))
+		return nil
+	}
+
+	fmt.Println(outPath)
+
+	if rp.RestoreOwner {
+		if err := sp.RestoreOwner(rp.OutputDir); err != nil {
+			fmt.Println(errtype.Join(ErrRestoreOwner(outPath), err))
+		}
+	}
+
+	if rp.RestoreXattrs {
+		if err := sp.RestoreXattrs(rp.OutputDir); err != nil {
+			fmt.Println(errtype.Join(ErrRestoreXattrs(outPath), err))
+		}
+	}
+
+	if err := sp.RestoreMode(rp.OutputDir); err != nil {
+		return errtype.Join(ErrRestoreMode(outPath), err)
+	}
+
+	if err := sp.RestoreTime(rp.OutputDir); err != nil {
+		return errtype.Join(ErrRestoreTime(outPath), err)
+	}
+
+	return nil
+}
+
+// Создает директорию из архива и добавляет ее в dirs.
+// Владелец, права доступа и временные метки
+// восстанавливаются позже в [RestoreDirsAttrs], иначе
+// распаковка содержимого изменит время модификации
+// или будет запрещена правами доступа.
+func RestoreDir(arcFile io.ReadSeeker, rp generic.RestoreParams, dirs *[]*header.DirItem) error {
+	di := &header.DirItem{}
+
+	if err := di.Read(arcFile); err != nil {
+		return errtype.Join(ErrReadDirHeader, err)
+	}
+
+	if err := di.RestorePath(rp.OutputDir); err != nil {
+		return errtype.Join(
+			ErrRestorePath(fp.Join(rp.OutputDir, di.PathOnDisk())), err,
+		)
+	}
+	*dirs = append(*dirs, di)
+
+	fmt.Println(fp.Join(rp.OutputDir, di.PathOnDisk()))
+
+	return nil
+}
+
+// Восстанавливает атрибуты распакованных директорий
+// dirs, начиная с самых глубоких
+func RestoreDirsAttrs(dirs []*header.DirItem, rp generic.RestoreParams) error {
+	// Вложенные директории следуют раньше родительских
+	slices.SortFunc(dirs, func(a, b *header.DirItem) int {
+		return strings.Compare(b.PathOnDisk(), a.PathOnDisk())
+	})
+
+	for _, di := range dirs {
+		outPath := fp.Join(rp.OutputDir, di.PathOnDisk())
+
+		if rp.RestoreOwner {
+			if err := di.RestoreOwner(rp.OutputDir); err != nil {
+				fmt.Println(errtype.Join(ErrRestoreOwner(outPath), err))
+			}
+		}
+
+		if rp.RestoreXattrs {
+			if err := di.RestoreXattrs(rp.OutputDir); err != nil {
+				fmt.Println(errtype.Join(ErrRestoreXattrs(outPath), err))
+			}
+		}
+
+		if err := di.RestoreMode(rp.OutputDir); err != nil {
+			return errtype.Join(ErrRestoreMode(outPath), err)
+		}
+
+		if err := di.RestoreTime(rp.OutputDir); err != nil {
+			return errtype.Join(ErrRestoreTime(outPath), err)
+		}
+	}
+
+	return nil
+}
+
+// Обрабатывает диалог замены файла, возвращает
+// true, если файл нужно пропустить
+func replaceInput(outPath string, replaceAll *bool) bool {
 	var input rune
 	stdin := bufio.NewReader(os.Stdin)
 	for {
@@ -98,7 +274,6 @@ func replaceInput(outPath string, arcFile io.ReadSeeker, replaceAll *bool) bool
 			*replaceAll = true
 		case 'y', 'д':
 		case 'n', 'н':
-			skipFileData(arcFile, true)
 			return true
 		default:
 			stdin.ReadString('\n')
@@ -110,11 +285,28 @@ func replaceInput(outPath string, arcFile io.ReadSeeker, replaceAll *bool) bool
 	return false
 }
 
-// Распаковывает файл
-func decompressFile(fi *header.FileItem, arcFile io.ReadSeeker, outPath string, ct c.Type) error {
-	outFile, err := os.Create(outPath)
+// Создает или обрезает файл для распаковки
+func createOutFile(outPath string) (*os.File, error) {
+	// Пока данные не записаны и права не восстановлены,
+	// файл доступен только владельцу
+	perm := os.FileMode(0666)
+	if header.HasFlag(header.FlagMode) {
+		perm = 0600
+	}
+
+	outFile, err := os.OpenFile(outPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, perm)
 	if err != nil {
-		return errtype.Join(ErrCreateOutFile, err)
+		return nil, errtype.Join(ErrCreateOutFile, err)
+	}
+
+	return outFile, nil
+}
+
+// Распаковывает файл декомпрессором, указанным в его заголовке
+func decompressFile(fi *header.FileItem, arcFile io.ReadSeeker, outPath string) error {
+	outFile, err := createOutFile(outPath)
+	if err != nil {
+		return err
 	}
 	defer outFile.Close()
 
@@ -128,6 +320,45 @@ func decompressFile(fi *header.FileItem, arcFile io.ReadSeeker, outPath string,
 		}
 	}
 
+	var out io.Writer = outFile
+	if regions := fi.Sparse(); len(regions) > 0 {
+		out = &sparseWriter{f: outFile, regions: regions}
+	} else if len(fi.Chunks()) > 0 { // Остальные фрагменты дописываются после
+		out = &sparseWriter{f: outFile, regions: fi.StoredRegions()}
+	}
+
+	outBuf := bufio.NewWriter(out)
+	damaged, err := decompressData(arcFile, fi.CompType(), outBuf)
+	if err != nil {
+		return err
+	}
+	fi.SetDamaged(damaged)
+
+	if err = outBuf.Flush(); err != nil {
+		return errtype.Join(ErrWriteOutBuf, err)
+	}
+
+	if len(fi.Sparse()) > 0 { // Дыра в конце файла задается размером
+		if err = outFile.Truncate(int64(fi.UcSize())); err != nil {
+			return errtype.Join(ErrWriteOutBuf, err)
+		}
+	}
+
+	if len(fi.Chunks()) > 0 {
+		if damaged, err = restoreChunks(fi, arcFile, outFile); err != nil {
+			return err
+		} else if damaged {
+			fi.SetDamaged(true)
+		}
+	}
+
+	return nil
+}
+
+// Распаковывает сжатые данные из arcFile компрессором
+// типа ct в out и читает контрольную сумму. Возвращает
+// true, если контрольная сумма не совпала.
+func decompressData(arcFile io.Reader, ct c.Type, out io.Writer) (damaged bool, err error) {
 	var (
 		ncpu            = generic.Ncpu()
 		decompressedBuf = generic.DecompBuffers()
@@ -141,22 +372,21 @@ func decompressFile(fi *header.FileItem, arcFile io.ReadSeeker, outPath string,
 		wg          = sync.WaitGroup{}
 	)
 
-	outBuf := bufio.NewWriter(outFile)
 	for eof != io.EOF {
 		if read, eof = loadCompressedBuf(arcFile, &calcCRC, ct); eof != nil && eof != io.EOF {
-			return errtype.Join(ErrReadCompressed, eof)
+			return false, errtype.Join(ErrReadCompressed, eof)
 		}
 
 		if read > 0 {
 			if err = decompressBuffers(); err != nil {
-				return errtype.Join(ErrDecompress, err)
+				return false, errtype.Join(ErrDecompress, err)
 			}
 
 			wg.Wait()
 
 			for i := 0; i < ncpu && decompressedBuf[i].Len() > 0; i++ {
 				if wrote, err = decompressedBuf[i].WriteTo(writeBuf); err != nil {
-					return errtype.Join(ErrWriteOutBuf, err)
+					return false, errtype.Join(ErrWriteOutBuf, err)
 				}
 				log.Println("В буфер записи записан блок размера:", wrote)
 			}
@@ -164,20 +394,16 @@ func decompressFile(fi *header.FileItem, arcFile io.ReadSeeker, outPath string,
 
 		if writeBuf.Len() >= writeBufSize || eof == io.EOF {
 			wg.Add(1)
-			go generic.FlushWriteBuffer(&wg, outBuf)
+			go generic.FlushWriteBuffer(&wg, out)
 		}
 	}
 	wg.Wait()
 
-	err = filesystem.BinaryRead(arcFile, &fileCRC)
-	if err != nil {
-		return errtype.Join(ErrReadCRC, err)
+	if err = filesystem.BinaryRead(arcFile, &fileCRC); err != nil {
+		return false, errtype.Join(ErrReadCRC, err)
 	}
-	fi.SetDamaged(calcCRC != fileCRC)
-
-	outBuf.Flush()
 
-	return nil
+	return calcCRC != fileCRC, nil
 }
 
 // Загружает данные в буферы сжатых данных
@@ -195,6 +421,7 @@ func loadCompressedBuf(arcBuf io.Reader, crc *uint32, ct c.Type) (read int64, er
 		n, bufferSize int64
 	)
 
+	generic.SetDecompType(ct)
 	for i := 0; i < ncpu; i++ {
 		if err = filesystem.BinaryRead(arcBuf, &bufferSize); err != nil {
 			return 0, errtype.Join(ErrReadCompLen, err)
@@ -217,7 +444,7 @@ func loadCompressedBuf(arcBuf io.Reader, crc *uint32, ct c.Type) (read int64, er
 		if decompressor[i] != nil {
 			decompressor[i].Reset(compressedBuf[i])
 		} else {
-			if decompressor[i], err = c.NewReader(ct, compressedBuf[i]); err != nil {
+			if decompressor[i], err = c.NewReaderDict(ct, compressedBuf[i], generic.Dictionary()); err != nil {
 				return 0, errtype.Join(ErrDecompInit, err)
 			}
 		}
@@ -265,7 +492,8 @@ func decompressBuffers() error {
 
 // Считывает данные сжатого файла из arcFile,
 // проверяет контрольную сумму и возвращает
-// количество прочитанных байт
+// количество прочитанных байт. ct -- тип
+// компрессора из заголовка файла.
 func CheckCRC(arcFile io.ReadSeeker, ct c.Type) (read header.Size, err error) {
 	var (
 		ncpu          = generic.Ncpu()
@@ -4,29 +4,45 @@ import "archiver/arc/internal/errors"
 
 // Ошибки при распаковке
 var (
-	ErrReadHeaders   = errors.ErrReadHeaders
-	ErrDecompressSym = errors.ErrDecompressSym
-	ErrSkipCRC       = errors.ErrSkipCRC
-	ErrCreateOutFile = errors.ErrCreateOutFile
-	ErrSkipEofCrc    = errors.ErrSkipEofCrc
-	ErrDecompress    = errors.ErrDecompress
-	ErrWriteOutBuf   = errors.ErrWriteOutBuf
-	ErrReadCompLen   = errors.ErrReadCompLen
-	ErrReadCompBuf   = errors.ErrReadCompBuf
-	ErrDecompInit    = errors.ErrDecompInit
-	ErrReadDecomp    = errors.ErrReadDecomp
-	ErrRestorePath   = errors.ErrRestorePath
-	ErrBufSize       = errors.ErrBufSize
+	ErrReadHeaders    = errors.ErrReadHeaders
+	ErrDecompressSym  = errors.ErrDecompressSym
+	ErrDecompressLink = errors.ErrDecompressLink
+	ErrSparseOverflow = errors.ErrSparseOverflow
+	ErrSolidOverflow  = errors.ErrSolidOverflow
+	ErrReadSolid      = errors.ErrReadSolid
+	ErrReadChunks     = errors.ErrReadChunks
+	ErrChunkNotFound  = errors.ErrChunkNotFound
+	ErrChunkOverflow  = errors.ErrChunkOverflow
+	ErrRestoreSpecial = errors.ErrRestoreSpecial
+	ErrSkipCRC        = errors.ErrSkipCRC
+	ErrCreateOutFile  = errors.ErrCreateOutFile
+	ErrSkipEofCrc     = errors.ErrSkipEofCrc
+	ErrDecompress     = errors.ErrDecompress
+	ErrWriteOutBuf    = errors.ErrWriteOutBuf
+	ErrReadCompLen    = errors.ErrReadCompLen
+	ErrReadCompBuf    = errors.ErrReadCompBuf
+	ErrDecompInit     = errors.ErrDecompInit
+	ErrReadDecomp     = errors.ErrReadDecomp
+	ErrRestorePath    = errors.ErrRestorePath
+	ErrRestoreMode    = errors.ErrRestoreMode
+	ErrRestoreOwner   = errors.ErrRestoreOwner
+	ErrRestoreXattrs  = errors.ErrRestoreXattrs
+	ErrRestoreTime    = errors.ErrRestoreTime
+	ErrBufSize        = errors.ErrBufSize
 )
 
 // Ошибки функции чтения
 var (
-	ErrReadCompressed = errors.ErrReadCompressed
-	ErrReadFileHeader = errors.ErrReadFileHeader
-	ErrReadSymHeader  = errors.ErrReadSymHeader
-	ErrReadCRC        = errors.ErrReadCRC
-	ErrSkipData       = errors.ErrSkipData
-	ErrReadHeaderType = errors.ErrReadHeaderType
-	ErrHeaderType     = errors.ErrHeaderType
-	ErrWrongCRC       = errors.ErrWrongCRC
+	ErrReadCompressed    = errors.ErrReadCompressed
+	ErrReadFileHeader    = errors.ErrReadFileHeader
+	ErrReadSymHeader     = errors.ErrReadSymHeader
+	ErrReadDirHeader     = errors.ErrReadDirHeader
+	ErrReadLinkHeader    = errors.ErrReadLinkHeader
+	ErrReadSpecialHeader = errors.ErrReadSpecialHeader
+	ErrReadCRC           = errors.ErrReadCRC
+	ErrSkipData          = errors.ErrSkipData
+	ErrReadHeaderType    = errors.ErrReadHeaderType
+	ErrHeaderType        = errors.ErrHeaderType
+	ErrWrongCRC          = errors.ErrWrongCRC
+	ErrReadIndex         = errors.ErrReadIndex
 )
@@ -9,28 +9,161 @@ import (
 	"log"
 	fp "path/filepath"
 	"sort"
+	"time"
 )
 
-// Читает заголовки из архива, определяет смещение данных
+// Читает заголовки из архива, определяет смещение данных.
+//
+// Если в архиве есть индекс, то заголовки читаются из
+// него, иначе архив просматривается последовательно.
+// Из элементов с одинаковым путем возвращается
+// записанный последним.
 func ReadHeaders(arcFile io.ReadSeekCloser, arcLenH int64) ([]header.Header, error) {
-	var headers []header.Header
-
-	handler := func(typ header.HeaderType, arcFile io.ReadSeekCloser) (err error) {
-		var h header.Header
-		switch typ {
-		case header.File:
-			h, err = readFileHeader(arcFile)
-		case header.Symlink:
-			h, err = readSymHeader(arcFile)
-		default:
-			return ErrHeaderType
+	entries, err := ReadEntries(arcFile, arcLenH)
+	if err != nil {
+		return nil, err
+	}
+	entries = header.LatestEntries(entries)
+
+	headers := make([]header.Header, 0, len(entries))
+	for _, e := range entries {
+		headers = append(headers, e.Header)
+	}
+
+	dirs := insertDirs(headers)
+	headers = append(headers, dirs...)
+	sort.Sort(header.ByPathInArc(headers))
+
+	return headers, nil
+}
+
+// Читает записи архива в порядке их следования вместе
+// со смещениями. Если в архиве есть индекс, то записи
+// читаются из него, иначе архив просматривается
+// последовательно.
+func ReadEntries(arcFile io.ReadSeekCloser, arcLenH int64) (entries []header.IndexEntry, err error) {
+	var ok bool
+
+	if header.HasFlag(header.FlagIndex) {
+		if entries, ok, err = ReadIndex(arcFile, arcLenH); err != nil {
+			return nil, errtype.Join(ErrReadIndex, err)
 		}
+	}
+
+	if !ok {
+		if entries, err = scanEntries(arcFile, arcLenH); err != nil {
+			return nil, err
+		}
+	}
+
+	// Возврат каретки в начало первого заголовка
+	arcFile.Seek(arcLenH, io.SeekStart)
+
+	return entries, nil
+}
+
+// Читает индекс архива. Если индекса в архиве
+// нет, то ok равен false.
+func ReadIndex(arcFile io.ReadSeeker, arcLenH int64) (entries []header.IndexEntry, ok bool, err error) {
+	var indexOffset int64
+
+	if indexOffset, ok, err = IndexOffset(arcFile, arcLenH); err != nil || !ok {
+		return nil, false, err
+	}
+
+	log.Println("Читаю индекс с позиции:", indexOffset)
+	if entries, err = header.ReadIndex(arcFile); err != nil {
+		return nil, false, err
+	}
+
+	return entries, true, nil
+}
+
+// Возвращает смещение индекса архива из завершающего
+// блока, оставляя arcFile после типа записи индекса.
+// Если индекса в архиве нет, то ok равен false.
+func IndexOffset(arcFile io.ReadSeeker, arcLenH int64) (offset int64, ok bool, err error) {
+	var typ header.HeaderType
+
+	if offset, ok, err = header.ReadFooter(arcFile); err != nil || !ok {
+		return 0, false, err
+	}
+
+	end, _ := arcFile.Seek(0, io.SeekEnd)
+	if offset < arcLenH || offset >= end-header.FooterLen {
+		return 0, false, nil // Сигнатура случайно совпала
+	}
+
+	arcFile.Seek(offset, io.SeekStart)
+	if err = filesystem.BinaryRead(arcFile, &typ); err != nil {
+		return 0, false, err
+	} else if typ != header.Index {
+		return 0, false, nil
+	}
+
+	return offset, true, nil
+}
+
+// Возвращает смещение конца записей архива:
+// начало индекса или конец файла архива
+func RecordsEnd(arcFile io.ReadSeeker, arcLenH int64) (int64, error) {
+	if header.HasFlag(header.FlagIndex) {
+		if offset, ok, err := IndexOffset(arcFile, arcLenH); err != nil {
+			return 0, errtype.Join(ErrReadIndex, err)
+		} else if ok {
+			return offset, nil
+		}
+	}
+
+	return arcFile.Seek(0, io.SeekEnd)
+}
+
+// Возвращает смещения записей, путь которых
+// повторяется в записанных позже элементах
+func ShadowedRecords(arcFile io.ReadSeekCloser, arcLenH int64) (map[int64]bool, error) {
+	entries, err := ReadEntries(arcFile, arcLenH)
+	if err != nil {
+		return nil, err
+	}
+
+	shadowed := make(map[int64]bool)
+	if latest := header.LatestEntries(entries); len(latest) < len(entries) {
+		for _, e := range entries {
+			shadowed[e.Offset] = true
+		}
+		for _, e := range latest {
+			delete(shadowed, e.Offset)
+		}
+	}
+
+	return shadowed, nil
+}
+
+// Пропускает запись типа typ вместе с данными
+func SkipRecord(typ header.HeaderType, arcFile io.ReadSeekCloser) error {
+	if _, err := readRecord(typ, arcFile); err != nil && err != io.EOF {
+		return errtype.Join(ErrReadHeaders, err)
+	}
+
+	return nil
+}
+
+// Последовательно читает записи из архива
+func scanEntries(arcFile io.ReadSeekCloser, arcLenH int64) ([]header.IndexEntry, error) {
+	var entries []header.IndexEntry
 
-		if err != nil && err != io.EOF {
+	handler := func(typ header.HeaderType, arcFile io.ReadSeekCloser) error {
+		pos, _ := arcFile.Seek(0, io.SeekCurrent)
+
+		h, err := readRecord(typ, arcFile)
+		if err == ErrHeaderType {
+			return err
+		} else if err != nil && err != io.EOF {
 			return errtype.Join(ErrReadHeaders, err)
 		}
-		if h != nil {
-			headers = append(headers, h)
+
+		if h != nil { // Смещение записи вместе с типом
+			entries = append(entries, header.IndexEntry{Offset: pos - 1, Header: h})
 		}
 		return nil
 	}
@@ -39,13 +172,27 @@ func ReadHeaders(arcFile io.ReadSeekCloser, arcLenH int64) ([]header.Header, err
 		return nil, errtype.Join(ErrReadHeaderType, err)
 	}
 
-	// Возврат каретки в начало первого заголовка
-	arcFile.Seek(arcLenH, io.SeekStart)
-	dirs := insertDirs(headers)
-	headers = append(headers, dirs...)
-	sort.Sort(header.ByPathInArc(headers))
+	return entries, nil
+}
 
-	return headers, nil
+// Читает запись типа typ, пропуская данные файла
+func readRecord(typ header.HeaderType, arcFile io.ReadSeeker) (header.Header, error) {
+	switch typ {
+	case header.File:
+		return readFileHeader(arcFile)
+	case header.Symlink:
+		return readSymHeader(arcFile)
+	case header.Directory:
+		return readDirHeader(arcFile)
+	case header.HardLink:
+		return readLinkHeader(arcFile)
+	case header.Fifo:
+		return readSpecialHeader(arcFile, &header.FifoItem{})
+	case header.Device:
+		return readSpecialHeader(arcFile, &header.DeviceItem{})
+	}
+
+	return nil, ErrHeaderType
 }
 
 // Читает и возвращает заголовки файлов
@@ -63,6 +210,10 @@ func readFileHeader(arcFile io.ReadSeeker) (*header.FileItem, error) {
 		return nil, errtype.Join(ErrReadFileHeader, err)
 	}
 
+	if !file.HasData() { // Данные в потоке solid-группы
+		return file, nil
+	}
+
 	pos, _ = arcFile.Seek(0, io.SeekCurrent)
 	log.Println("Читаю размер сжатых данных с позиции:", pos)
 	if dataSize, err = skipFileData(arcFile, false); err == io.EOF {
@@ -80,6 +231,41 @@ func readFileHeader(arcFile io.ReadSeeker) (*header.FileItem, error) {
 	return file, nil
 }
 
+// Читает запись файла по смещению offset,
+// оставляя arcFile в начале данных файла
+func readFileRecord(arcFile io.ReadSeeker, offset int64) (*header.FileItem, error) {
+	var (
+		typ header.HeaderType
+		fi  = &header.FileItem{}
+	)
+
+	if _, err := arcFile.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	if err := filesystem.BinaryRead(arcFile, &typ); err != nil {
+		return nil, err
+	} else if typ != header.File {
+		return nil, ErrHeaderType
+	}
+
+	if err := fi.Read(arcFile); err != nil {
+		return nil, errtype.Join(ErrReadFileHeader, err)
+	}
+
+	return fi, nil
+}
+
+// Возвращает смещение сжатых данных файла,
+// запись которого начинается со смещения offset
+func DataOffset(arcFile io.ReadSeeker, offset int64) (int64, error) {
+	if _, err := readFileRecord(arcFile, offset); err != nil {
+		return 0, err
+	}
+
+	return arcFile.Seek(0, io.SeekCurrent)
+}
+
 // Читает заголовок символьной ссылки из архива
 func readSymHeader(arcFile io.ReadSeeker) (sym *header.SymItem, err error) {
 	sym = &header.SymItem{}
@@ -94,7 +280,49 @@ func readSymHeader(arcFile io.ReadSeeker) (sym *header.SymItem, err error) {
 	return sym, nil
 }
 
-// Вставляет в срез с заголовками пути к директориям
+// Читает заголовок директории из архива
+func readDirHeader(arcFile io.ReadSeeker) (di *header.DirItem, err error) {
+	di = &header.DirItem{}
+	pos, _ := arcFile.Seek(0, io.SeekCurrent)
+	log.Println("Читаю заголовок директории с позиции:", pos)
+	if err = di.Read(arcFile); err == io.EOF {
+		return nil, err
+	} else if err != nil {
+		return nil, errtype.Join(ErrReadDirHeader, err)
+	}
+
+	return di, nil
+}
+
+// Читает заголовок жесткой ссылки из архива
+func readLinkHeader(arcFile io.ReadSeeker) (li *header.LinkItem, err error) {
+	li = &header.LinkItem{}
+	pos, _ := arcFile.Seek(0, io.SeekCurrent)
+	log.Println("Читаю заголовок жесткой ссылки с позиции:", pos)
+	if err = li.Read(arcFile); err == io.EOF {
+		return nil, err
+	} else if err != nil {
+		return nil, errtype.Join(ErrReadLinkHeader, err)
+	}
+
+	return li, nil
+}
+
+// Читает заголовок именованного канала или устройства в sp
+func readSpecialHeader(arcFile io.ReadSeeker, sp specialItem) (header.Header, error) {
+	pos, _ := arcFile.Seek(0, io.SeekCurrent)
+	log.Println("Читаю заголовок специального файла с позиции:", pos)
+	if err := sp.Read(arcFile); err == io.EOF {
+		return nil, err
+	} else if err != nil {
+		return nil, errtype.Join(ErrReadSpecialHeader, err)
+	}
+
+	return sp, nil
+}
+
+// Вставляет в срез с заголовками пути к директориям,
+// для которых в архиве нет собственных записей
 func insertDirs(headers []header.Header) []header.Header {
 	var (
 		dirs  []header.Header
@@ -103,14 +331,20 @@ func insertDirs(headers []header.Header) []header.Header {
 		path  string
 	)
 
+	for _, h := range headers {
+		if _, ok := h.(*header.DirItem); ok {
+			seen[h.PathInArc()] = struct{}{}
+		}
+	}
+
 	for _, h := range headers {
 		if _, ok := h.(*header.SymItem); ok {
 			continue // Пропускаем символьные ссылки
 		}
 
 		path = fp.Dir(h.PathInArc())
-		if _, ok := seen[path]; ok {
-			continue // Такой путь уже есть
+		if _, ok := seen[path]; ok || path == "." {
+			continue // Такой путь уже есть или элемент в корне
 		}
 
 		parts = filesystem.SplitPath(path)
@@ -119,7 +353,8 @@ func insertDirs(headers []header.Header) []header.Header {
 			path = fp.Join(path, p)
 			if _, ok := seen[path]; !ok {
 				seen[path] = struct{}{} // Такого пути нет
-				dirs = append(dirs, header.NewDirItem(path))
+				b, _ := header.NewBase(path, time.Time{}, time.Time{})
+				dirs = append(dirs, header.NewDirItem(b))
 			}
 		}
 	}
@@ -0,0 +1,126 @@
+package decompress
+
+import (
+	"archiver/arc/internal/header"
+	"archiver/errtype"
+	"bytes"
+	"io"
+)
+
+// Распакованный поток последней прочитанной solid-группы.
+// У поврежденной группы, пропущенной при проверке
+// целостности, поток не распаковывается.
+var solidCache struct {
+	valid   bool
+	group   int64 // Смещение записи первого файла группы
+	data    []byte
+	damaged bool
+}
+
+// Сбрасывает распакованный поток solid-группы,
+// вызывается перед работой с новым архивом
+func ResetSolid() {
+	solidCache.valid, solidCache.data = false, nil
+}
+
+// Запоминает, что solid-группа файла fi повреждена
+func markSolidDamaged(fi *header.FileItem) {
+	solidCache.valid, solidCache.group = true, fi.Solid().Group
+	solidCache.data, solidCache.damaged = nil, true
+}
+
+// Восстанавливает файл fi из потока его solid-группы.
+// Если integ установлен и группа повреждена, то файл
+// не создается и возвращается true.
+func restoreSolid(fi *header.FileItem, arcFile io.ReadSeeker, outPath string, integ bool) (skip bool, err error) {
+	data, damaged, err := solidStream(arcFile, fi, integ)
+	if err != nil {
+		return false, errtype.Join(ErrReadSolid, err)
+	} else if damaged && integ {
+		return true, nil
+	}
+	fi.SetDamaged(damaged)
+
+	ref := fi.Solid()
+	end := ref.Offset + int64(fi.UcSize())
+	if ref.Offset < 0 || end > int64(len(data)) {
+		return false, ErrSolidOverflow
+	}
+
+	outFile, err := createOutFile(outPath)
+	if err != nil {
+		return false, err
+	}
+	defer outFile.Close()
+
+	if _, err = outFile.Write(data[ref.Offset:end]); err != nil {
+		return false, errtype.Join(ErrWriteOutBuf, err)
+	}
+
+	return false, nil
+}
+
+// Возвращает распакованный поток solid-группы файла fi и
+// признак ее повреждения. Данные группы следуют за записью
+// первого файла, для остальных файлов группа читается по
+// ее смещению, после чего позиция в arcFile восстанавливается.
+// Если integ установлен, то поврежденная группа не
+// распаковывается.
+func solidStream(arcFile io.ReadSeeker, fi *header.FileItem, integ bool) (data []byte, damaged bool, err error) {
+	var (
+		ref    = fi.Solid()
+		head   = fi
+		cached = solidCache.valid && solidCache.group == ref.Group
+	)
+
+	if ref.Kind == header.SolidHead && cached { // Группа уже распакована
+		if _, err = skipFileData(arcFile, true); err != nil {
+			return nil, false, err
+		}
+		return solidCache.data, solidCache.damaged, nil
+	} else if ref.Kind == header.SolidMember {
+		if cached {
+			return solidCache.data, solidCache.damaged, nil
+		}
+
+		pos, _ := arcFile.Seek(0, io.SeekCurrent)
+		defer arcFile.Seek(pos, io.SeekStart)
+
+		if head, err = readSolidHead(arcFile, ref.Group); err != nil {
+			return nil, false, err
+		}
+
+		if integ {
+			start, _ := arcFile.Seek(0, io.SeekCurrent)
+			if _, err = CheckCRC(arcFile, head.CompType()); err == ErrWrongCRC {
+				markSolidDamaged(fi)
+				return nil, true, nil
+			} else if err != nil {
+				return nil, false, err
+			}
+			arcFile.Seek(start, io.SeekStart)
+		}
+	}
+
+	var buf bytes.Buffer
+	if damaged, err = decompressData(arcFile, head.CompType(), &buf); err != nil {
+		return nil, false, err
+	}
+	solidCache.valid, solidCache.group = true, ref.Group
+	solidCache.data, solidCache.damaged = buf.Bytes(), damaged
+
+	return solidCache.data, damaged, nil
+}
+
+// Читает запись первого файла solid-группы по смещению
+// group, оставляя arcFile в начале данных группы
+func readSolidHead(arcFile io.ReadSeeker, group int64) (*header.FileItem, error) {
+	head, err := readFileRecord(arcFile, group)
+	if err != nil {
+		return nil, err
+	} else if head.Solid().Kind != header.SolidHead {
+		return nil, ErrReadSolid
+	}
+
+	return head, nil
+}
@@ -0,0 +1,46 @@
+package decompress
+
+import (
+	"archiver/arc/internal/header"
+	"io"
+	"os"
+)
+
+// Писатель, раскладывающий распакованные данные
+// по областям разреженного файла. Дыры между
+// областями пропускаются перемещением в файле.
+type sparseWriter struct {
+	f       *os.File
+	regions []header.SparseRegion // Оставшиеся области данных
+	left    int64                 // Остаток текущей области
+}
+
+// Реализация io.Writer
+func (sw *sparseWriter) Write(p []byte) (n int, err error) {
+	for len(p) > 0 {
+		for sw.left == 0 {
+			if len(sw.regions) == 0 {
+				return n, ErrSparseOverflow
+			}
+
+			region := sw.regions[0]
+			sw.regions = sw.regions[1:]
+			if _, err = sw.f.Seek(region.Offset, io.SeekStart); err != nil {
+				return n, err
+			}
+			sw.left = region.Length
+		}
+
+		chunk := p[:min(int64(len(p)), sw.left)]
+		wrote, err := sw.f.Write(chunk)
+		n += wrote
+		sw.left -= int64(wrote)
+		p = p[wrote:]
+
+		if err != nil {
+			return n, err
+		}
+	}
+
+	return n, nil
+}
@@ -0,0 +1,86 @@
+package decompress
+
+import (
+	"archiver/arc/internal/generic"
+	c "archiver/compressor"
+	"archiver/errtype"
+	"archiver/filesystem"
+	"bytes"
+	"hash/crc32"
+	"io"
+)
+
+// Читатель распакованных данных файла. Блоки распаковываются
+// по одному в собственный буфер, а не в общие буферы
+// распаковки, поэтому данные можно сразу сжимать заново.
+type DataReader struct {
+	arcFile io.Reader
+	ct      c.Type       // Компрессор данных файла
+	block   bytes.Buffer // Распакованный блок
+	crc     uint32       // Контрольная сумма прочитанных блоков
+	eof     bool         // Прочитан признак конца данных
+	damaged bool         // Контрольная сумма не совпала
+}
+
+// Создает [DataReader] данных, сжатых компрессором ct,
+// на начале которых стоит arcFile
+func NewDataReader(arcFile io.Reader, ct c.Type) *DataReader {
+	return &DataReader{arcFile: arcFile, ct: ct}
+}
+
+// Реализация [io.Reader]. После признака конца данных
+// читается и проверяется контрольная сумма.
+func (dr *DataReader) Read(p []byte) (int, error) {
+	for dr.block.Len() == 0 {
+		if dr.eof {
+			return 0, io.EOF
+		} else if err := dr.next(); err != nil {
+			return 0, err
+		}
+	}
+
+	return dr.block.Read(p)
+}
+
+// Сообщает, что контрольная сумма прочитанных
+// данных не совпала с записанной в архиве
+func (dr *DataReader) Damaged() bool { return dr.damaged }
+
+// Читает и распаковывает следующий блок данных
+func (dr *DataReader) next() error {
+	var size int64
+	if err := filesystem.BinaryRead(dr.arcFile, &size); err != nil {
+		return errtype.Join(ErrReadCompLen, err)
+	}
+
+	if size == -1 {
+		var crc uint32
+		if err := filesystem.BinaryRead(dr.arcFile, &crc); err != nil {
+			return errtype.Join(ErrReadCRC, err)
+		}
+		dr.eof, dr.damaged = true, crc != dr.crc
+		return nil
+	} else if generic.CheckBufferSize(size) {
+		return ErrBufSize(size)
+	}
+
+	compressed := make([]byte, size)
+	if _, err := io.ReadFull(dr.arcFile, compressed); err != nil {
+		return errtype.Join(ErrReadCompBuf, err)
+	}
+	dr.crc ^= crc32.Checksum(compressed, generic.CRCTable())
+
+	decompressor, err := c.NewReaderDict(dr.ct, bytes.NewReader(compressed), generic.Dictionary())
+	if err != nil {
+		return errtype.Join(ErrDecompInit, err)
+	}
+	defer decompressor.Close()
+
+	dr.block.Reset()
+	if _, err = dr.block.ReadFrom(decompressor); err != nil &&
+		err != io.EOF && err != io.ErrUnexpectedEOF {
+		return errtype.Join(ErrReadDecomp, err)
+	}
+
+	return nil
+}
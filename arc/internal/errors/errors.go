@@ -14,31 +14,91 @@ func ErrNotArc(path string) error {
 	return fmt.Errorf("'%s' не архив Arc", path)
 }
 
+func ErrVersion(version byte) error {
+	return fmt.Errorf("неподдерживаемая версия (%d) формата архива", version)
+}
+
+func ErrUnsupportedFlags(flags uint32) error {
+	return fmt.Errorf(
+		"архив использует возможности (флаги 0x%X), не поддерживаемые этой версией программы",
+		flags,
+	)
+}
+
 var ErrUnknownComp = c.ErrUnknownComp
 
+// Ошибки при дополнении архива
+var (
+	ErrUpdateFormat   = fmt.Errorf("формат архива не поддерживает дополнение с выбранными параметрами")
+	ErrUpdateCompType = func(ct c.Type) error {
+		return fmt.Errorf("архив сжат %s, дополнять его можно только тем же компрессором", ct)
+	}
+	ErrUpdateArc = fmt.Errorf("ошибка дополнения архива")
+)
+
 // Ошибки при сжатии
 var (
-	ErrNoEntries         = fmt.Errorf("нет элементов для сжатия")
-	ErrCompressorInit    = fmt.Errorf("ошибка иницализации компрессора")
-	ErrWriteArcHeaders   = fmt.Errorf("ошибка записи заголовка архива")
-	ErrWriteFileHeader   = fmt.Errorf("ошибка записи заголовка файла")
-	ErrCompressFile      = fmt.Errorf("ошибка сжатия файла")
-	ErrReadUncompressed  = fmt.Errorf("ошибка чтения несжатых блоков")
-	ErrCompress          = fmt.Errorf("ошибка сжатия буфферов")
-	ErrWriteBufLen       = fmt.Errorf("ошибка записи длины блока")
-	ErrWriteCompressBuf  = fmt.Errorf("ошибка чтения из буфера сжатых данных")
-	ErrReadUncompressBuf = fmt.Errorf("ошибка чтения в несжатый буфер")
-	ErrWriteEOF          = fmt.Errorf("ошибка записи EOF (-1)")
-	ErrWriteCRC          = fmt.Errorf("ошибка записи CRC")
-	ErrWriteCompressor   = fmt.Errorf("ошибка записи в компрессор")
-	ErrCloseCompressor   = fmt.Errorf("ошибка закрытия компрессора")
-	ErrFetchDirs         = fmt.Errorf("не могу получить директории")
+	ErrNoEntries          = fmt.Errorf("нет элементов для сжатия")
+	ErrCompressorInit     = fmt.Errorf("ошибка иницализации компрессора")
+	ErrWriteArcHeaders    = fmt.Errorf("ошибка записи заголовка архива")
+	ErrWriteFileHeader    = fmt.Errorf("ошибка записи заголовка файла")
+	ErrWriteDirHeader     = fmt.Errorf("ошибка записи заголовка директории")
+	ErrWriteLinkHeader    = fmt.Errorf("ошибка записи заголовка жесткой ссылки")
+	ErrWriteSpecialHeader = fmt.Errorf("ошибка записи заголовка специального файла")
+	ErrCompressFile       = fmt.Errorf("ошибка сжатия файла")
+	ErrReadUncompressed   = fmt.Errorf("ошибка чтения несжатых блоков")
+	ErrCompress           = fmt.Errorf("ошибка сжатия буфферов")
+	ErrWriteBufLen        = fmt.Errorf("ошибка записи длины блока")
+	ErrWriteCompressBuf   = fmt.Errorf("ошибка чтения из буфера сжатых данных")
+	ErrReadUncompressBuf  = fmt.Errorf("ошибка чтения в несжатый буфер")
+	ErrWriteEOF           = fmt.Errorf("ошибка записи EOF (-1)")
+	ErrWriteCRC           = fmt.Errorf("ошибка записи CRC")
+	ErrWriteCompressor    = fmt.Errorf("ошибка записи в компрессор")
+	ErrCloseCompressor    = fmt.Errorf("ошибка закрытия компрессора")
+	ErrFetchDirs          = fmt.Errorf("не могу получить директории")
+	ErrWriteIndex         = fmt.Errorf("ошибка записи индекса архива")
+	ErrCopyRecord         = fmt.Errorf("ошибка переноса записи архива")
 
 	ErrLongPath = header.ErrLongPath
 
 	ErrOpenFileCompress = func(path string) error {
 		return fmt.Errorf("не могу открыть входной файл '%s' для сжатия", path)
 	}
+
+	ErrCopySolid = func(path string) error {
+		return fmt.Errorf("первый файл solid-группы '%s' не перенесен", path)
+	}
+)
+
+// Ошибки при изменении архива
+var (
+	ErrEditArc      = fmt.Errorf("ошибка изменения архива")
+	ErrRemoveChunks = func(path string) error {
+		return fmt.Errorf("на фрагменты '%s' ссылаются оставшиеся файлы", path)
+	}
+	ErrRemoveLinkTarget = func(path, link string) error {
+		return fmt.Errorf("на '%s' ссылается оставшаяся жесткая ссылка '%s'", path, link)
+	}
+	ErrRenameExists = func(path string) error {
+		return fmt.Errorf("путь '%s' уже есть в архиве", path)
+	}
+	ErrMergeArc    = fmt.Errorf("ошибка объединения архивов")
+	ErrMergeFormat = func(path string) error {
+		return fmt.Errorf("формат архива '%s' не подходит для объединения или отличается от формата остальных архивов", path)
+	}
+	ErrMergeDict = func(path string) error {
+		return fmt.Errorf("словарь архива '%s' отличается от словаря остальных архивов", path)
+	}
+	ErrMergeLzw = func(path string) error {
+		return fmt.Errorf("параметры LZW архива '%s' отличаются от параметров остальных архивов", path)
+	}
+	ErrMergeDuplicate = func(path string) error {
+		return fmt.Errorf("путь '%s' есть в нескольких архивах", path)
+	}
+	ErrTranscodeArc     = fmt.Errorf("ошибка перекодирования архива")
+	ErrTranscodeDamaged = func(path string) error {
+		return fmt.Errorf("данные '%s' повреждены, контрольная сумма не совпадает", path)
+	}
 )
 
 // Ошибки при распаковке
@@ -46,20 +106,47 @@ var (
 	ErrReadHeaders    = fmt.Errorf("ошибка чтения заголовоков")
 	ErrDecompressFile = fmt.Errorf("ошибка распаковки файла")
 	ErrDecompressSym  = fmt.Errorf("ошибка распаковки символьной ссылки")
-	ErrSkipCRC        = fmt.Errorf("ошибка пропуска CRC")
-	ErrCreateOutFile  = fmt.Errorf("не могу создать файл")
-	ErrSkipEofCrc     = fmt.Errorf("ошибка пропуска признака EOF")
-	ErrDecompress     = fmt.Errorf("ошибка распаковки буферов")
-	ErrWriteOutBuf    = fmt.Errorf("ошибка записи в буфер выхода")
-	ErrReadCompLen    = fmt.Errorf("ошибка чтения размера блока")
-	ErrReadCompBuf    = fmt.Errorf("ошибка чтения блока")
-	ErrDecompInit     = fmt.Errorf("ошибка иницализации декомпрессора")
-	ErrReadDecomp     = fmt.Errorf("ошибка чтения декомпрессора")
+	ErrDecompressDir  = fmt.Errorf("ошибка распаковки директории")
+	ErrDecompressLink = fmt.Errorf("ошибка распаковки жесткой ссылки")
+	ErrSparseOverflow = fmt.Errorf("данные файла выходят за пределы карты областей")
+	ErrSolidOverflow  = fmt.Errorf("данные файла выходят за пределы потока solid-группы")
+	ErrReadSolid      = fmt.Errorf("ошибка чтения solid-группы")
+	ErrReadChunks     = fmt.Errorf("ошибка чтения фрагментов файла")
+	ErrChunkNotFound  = fmt.Errorf("фрагмент файла не найден в архиве")
+	ErrChunkOverflow  = fmt.Errorf("фрагмент выходит за пределы данных хранящего его файла")
+	ErrRestoreSpecial = func(path string) error {
+		return fmt.Errorf("не могу создать специальный файл '%s'", path)
+	}
+	ErrSkipCRC       = fmt.Errorf("ошибка пропуска CRC")
+	ErrCreateOutFile = fmt.Errorf("не могу создать файл")
+	ErrSkipEofCrc    = fmt.Errorf("ошибка пропуска признака EOF")
+	ErrDecompress    = fmt.Errorf("ошибка распаковки буферов")
+	ErrWriteOutBuf   = fmt.Errorf("ошибка записи в буфер выхода")
+	ErrReadCompLen   = fmt.Errorf("ошибка чтения размера блока")
+	ErrReadCompBuf   = fmt.Errorf("ошибка чтения блока")
+	ErrDecompInit    = fmt.Errorf("ошибка иницализации декомпрессора")
+	ErrReadDecomp    = fmt.Errorf("ошибка чтения декомпрессора")
 
 	ErrRestorePath = func(path string) error {
 		return fmt.Errorf("не могу создать путь для '%s'", path)
 	}
 
+	ErrRestoreTime = func(path string) error {
+		return fmt.Errorf("не могу восстановить временные метки '%s'", path)
+	}
+
+	ErrRestoreOwner = func(path string) error {
+		return fmt.Errorf("не могу восстановить владельца '%s'", path)
+	}
+
+	ErrRestoreMode = func(path string) error {
+		return fmt.Errorf("не могу восстановить права доступа к '%s'", path)
+	}
+
+	ErrRestoreXattrs = func(path string) error {
+		return fmt.Errorf("не могу восстановить расширенные атрибуты '%s'", path)
+	}
+
 	ErrBufSize = func(bufferSize int64) error {
 		return fmt.Errorf("некорректный размер (%d) блока сжатых данных", bufferSize)
 	}
@@ -74,22 +161,35 @@ var (
 
 // Ошибки функции чтения
 var (
-	ErrOpenArc        = fmt.Errorf("не могу открыть файл архива")
-	ErrReadMagic      = fmt.Errorf("ошибка чтения сигнатуры")
-	ErrReadCompressed = fmt.Errorf("ошибка чтения сжатых блоков")
-	ErrReadFileHeader = fmt.Errorf("ошибка чтения заголовка файла")
-	ErrReadSymHeader  = fmt.Errorf("ошибка чтения заголовка символьной ссылки")
-	ErrReadCompSize   = fmt.Errorf("ошибка чтения размера сжатых данных")
-	ErrReadCRC        = fmt.Errorf("ошибка чтения CRC")
-	ErrSkipData       = fmt.Errorf("ошибка пропуска блока сжатых данных")
-	ErrReadHeaderType = fmt.Errorf("ошибка чтения типа")
-	ErrHeaderType     = fmt.Errorf("неизвестный тип")
+	ErrOpenArc           = fmt.Errorf("не могу открыть файл архива")
+	ErrReadMagic         = fmt.Errorf("ошибка чтения сигнатуры")
+	ErrReadArcHeader     = fmt.Errorf("ошибка чтения заголовка архива")
+	ErrReadCompressed    = fmt.Errorf("ошибка чтения сжатых блоков")
+	ErrReadFileHeader    = fmt.Errorf("ошибка чтения заголовка файла")
+	ErrReadSymHeader     = fmt.Errorf("ошибка чтения заголовка символьной ссылки")
+	ErrReadDirHeader     = fmt.Errorf("ошибка чтения заголовка директории")
+	ErrReadLinkHeader    = fmt.Errorf("ошибка чтения заголовка жесткой ссылки")
+	ErrReadSpecialHeader = fmt.Errorf("ошибка чтения заголовка специального файла")
+	ErrReadCompSize      = fmt.Errorf("ошибка чтения размера сжатых данных")
+	ErrReadCRC           = fmt.Errorf("ошибка чтения CRC")
+	ErrSkipData          = fmt.Errorf("ошибка пропуска блока сжатых данных")
+	ErrReadHeaderType    = fmt.Errorf("ошибка чтения типа")
+	ErrReadIndex         = fmt.Errorf("ошибка чтения индекса архива")
+	ErrReadDict          = fmt.Errorf("ошибка чтения словаря архива")
+	ErrReadLzwParams     = fmt.Errorf("ошибка чтения параметров LZW")
+	ErrDictSize          = func(size uint32) error {
+		return fmt.Errorf("некорректный размер (%d) словаря архива", size)
+	}
+	ErrHeaderType = fmt.Errorf("неизвестный тип")
 )
 
 // Ошибки функции записи
 var (
 	ErrCreateArc     = fmt.Errorf("не могу создать файл архива")
 	ErrWriteMagic    = fmt.Errorf("ошибка записи сигнатуры")
+	ErrWriteVersion  = fmt.Errorf("ошибка записи версии формата")
 	ErrWriteCompType = fmt.Errorf("ошибка записи типа компрессора")
+	ErrWriteDict     = fmt.Errorf("ошибка записи словаря архива")
+	ErrWriteLzw      = fmt.Errorf("ошибка записи параметров LZW")
 	ErrFlushWrBuf    = fmt.Errorf("ошибка сброса буфера записи на диск")
 )
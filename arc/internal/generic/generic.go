@@ -18,8 +18,24 @@ type RestoreParams struct {
 	Integ     bool
 	Ct        c.Type  // Тип компрессора
 	Cl        c.Level // Уровень сжатия
+	// Флаг сохранения несжимаемых файлов без сжатия
+	AutoStore bool
 	// Флаг замены файлов без подтверждения
 	ReplaceAll bool
+	// Флаг восстановления владельца и группы
+	RestoreOwner bool
+	// Флаг восстановления расширенных атрибутов
+	RestoreXattrs bool
+	// Предустановленный словарь для компрессоров
+	Dict []byte
+	// Флаг построения словаря из выборки входных файлов
+	AutoDict bool
+	// Параметры кодирования LZW
+	Lzw c.LzwParams
+	// Флаг сжатия мелких файлов общим потоком
+	Solid bool
+	// Флаг дедупликации фрагментов файлов
+	Dedup bool
 }
 
 // Базовый размер буфера
@@ -39,6 +55,15 @@ var (
 	decompressor    = make([]*c.Reader, ncpu)
 	writeBuf        *bytes.Buffer
 	writeBufSize    int
+
+	// Параметры инициализированных компрессоров
+	compCt   c.Type
+	compCl   c.Level
+	compInit bool
+	// Тип созданных декомпрессоров
+	decompCt c.Type
+	// Предустановленный словарь текущего архива
+	dict []byte
 )
 
 func BufferSize() int { return bufferSize }
@@ -53,6 +78,29 @@ func Decompressors() []*c.Reader     { return decompressor }
 func WriteBuffer() *bytes.Buffer { return writeBuf }
 func WriteBufSize() int          { return writeBufSize }
 
+// Возвращает предустановленный словарь текущего архива
+func Dictionary() []byte { return dict }
+
+// Устанавливает предустановленный словарь текущего архива.
+// Созданные компрессоры и декомпрессоры сбрасываются.
+func SetDictionary(d []byte) {
+	dict = d
+	compInit = false
+	ResetDecomp()
+}
+
+// Устанавливает параметры LZW текущего архива
+// и сбрасывает созданные компрессоры
+func SetLzwParams(lp c.LzwParams) error {
+	if err := c.SetLzwParams(lp); err != nil {
+		return err
+	}
+	compInit = false
+	ResetDecomp()
+
+	return nil
+}
+
 func SetWriteBufSize(size int) {
 	writeBufSize = size
 	writeBuf = bytes.NewBuffer(make([]byte, 0, writeBufSize))
@@ -79,14 +127,22 @@ func CheckBufferSize(bufferSize int64) bool {
 	return bufferSize < 0 || bufferSize>>1 > bufferSize
 }
 
-// Инициализирует компрессоры
-func InitCompressors(rp RestoreParams) (err error) {
+// Инициализирует компрессоры типа ct с уровнем сжатия cl.
+// Если компрессоры уже созданы с этими параметрами, то
+// повторная инициализация не выполняется.
+func InitCompressors(ct c.Type, cl c.Level) (err error) {
+	if compInit && ct == compCt && cl == compCl {
+		return nil
+	}
+
 	for i := 0; i < ncpu; i++ { // Инициализация компрессоров
-		compressor[i], err = c.NewWriter(rp.Ct, compressedBuf[i], rp.Cl)
+		compressor[i], err = c.NewWriterDict(ct, compressedBuf[i], cl, dict)
 		if err != nil {
+			compInit = false
 			return err
 		}
 	}
+	compCt, compCl, compInit = ct, cl, true
 
 	return nil
 }
@@ -98,6 +154,15 @@ func ResetDecomp() {
 	}
 }
 
+// Устанавливает тип декомпрессоров. Если тип
+// изменился, то созданные декомпрессоры сбрасываются.
+func SetDecompType(ct c.Type) {
+	if ct != decompCt {
+		ResetDecomp()
+		decompCt = ct
+	}
+}
+
 // Прототип функции-обработчика заголовков
 type ProcHeaderHandler = func(header.HeaderType, io.ReadSeekCloser) error
 
@@ -115,6 +180,10 @@ func ProcessHeaders(arcFile io.ReadSeekCloser, arcLenH int64, handler ProcHeader
 			return err
 		}
 
+		if typ == header.Index { // Дальше только индекс архива
+			return nil
+		}
+
 		if err := handler(typ, arcFile); err != nil {
 			return err
 		}
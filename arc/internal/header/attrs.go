@@ -0,0 +1,356 @@
+package header
+
+import (
+	"archiver/filesystem"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"os"
+	"os/user"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Биты прав доступа, восстанавливаемые при распаковке
+const permBits = os.ModePerm | specialBits
+
+// Биты setuid, setgid и sticky. Восстанавливаются, только
+// если владелец или группа элемента совпадают с архивными.
+const specialBits = os.ModeSetuid | os.ModeSetgid | os.ModeSticky
+
+// Атрибуты элемента файловой системы
+type attrs struct {
+	mode  os.FileMode // Тип и права доступа
+	uid   uint32      // Идентификатор владельца
+	gid   uint32      // Идентификатор группы
+	uname string      // Имя владельца
+	gname string      // Имя группы
+	// Расширенные атрибуты, в том числе списки
+	// контроля доступа POSIX и метки SELinux
+	xattrs map[string][]byte
+}
+
+// Возвращает тип и права доступа элемента
+func (a attrs) Mode() os.FileMode { return a.mode }
+
+// Устанавливает тип и права доступа элемента
+func (a *attrs) SetMode(mode os.FileMode) { a.mode = mode }
+
+// Форматирует права доступа для вывода статистики
+func (a attrs) modeString() string {
+	if !HasFlag(FlagMode) {
+		return "-"
+	}
+
+	// Символьные устройства помечаются одной буквой,
+	// чтобы ширина колонки не менялась
+	if a.mode&os.ModeCharDevice != 0 {
+		return strings.TrimPrefix(a.mode.String(), "D")
+	}
+
+	return a.mode.String()
+}
+
+// Возвращает идентификаторы и имена владельца и группы
+func (a attrs) Owner() (uid, gid uint32, uname, gname string) {
+	return a.uid, a.gid, a.uname, a.gname
+}
+
+// Устанавливает идентификаторы и имена владельца и группы
+func (a *attrs) SetOwner(uid, gid uint32, uname, gname string) {
+	a.uid, a.gid, a.uname, a.gname = uid, gid, uname, gname
+}
+
+// Возвращает расширенные атрибуты элемента
+func (a attrs) Xattrs() map[string][]byte { return a.xattrs }
+
+// Устанавливает расширенные атрибуты элемента
+func (a *attrs) SetXattrs(xattrs map[string][]byte) { a.xattrs = xattrs }
+
+// Помечает элементы с расширенными атрибутами
+// в выводе статистики
+func (a attrs) xattrMark() string {
+	if len(a.xattrs) > 0 {
+		return "@"
+	}
+
+	return ""
+}
+
+// Десериализует атрибуты из r
+func (a *attrs) read(r io.Reader) (err error) {
+	if HasFlag(FlagMode) {
+		var mode uint32
+		if err = filesystem.BinaryRead(r, &mode); err != nil {
+			return err
+		}
+		a.mode = os.FileMode(mode)
+	}
+
+	if HasFlag(FlagOwner) {
+		if err = filesystem.BinaryRead(r, &a.uid); err != nil {
+			return err
+		}
+		if err = filesystem.BinaryRead(r, &a.gid); err != nil {
+			return err
+		}
+		if a.uname, err = readString(r); err != nil {
+			return err
+		}
+		if a.gname, err = readString(r); err != nil {
+			return err
+		}
+	}
+
+	if HasFlag(FlagXattr) {
+		return a.readXattrs(r)
+	}
+
+	return nil
+}
+
+// Ограничения ядра Linux на расширенные атрибуты
+const (
+	xattrSizeMax = 64 << 10 // XATTR_SIZE_MAX, размер значения
+	xattrListMax = 64 << 10 // XATTR_LIST_MAX, размер списка имен
+)
+
+// Десериализует расширенные атрибуты из r
+func (a *attrs) readXattrs(r io.Reader) (err error) {
+	var (
+		count  uint16
+		length uint32
+		name   string
+	)
+
+	if err = filesystem.BinaryRead(r, &count); err != nil {
+		return err
+	}
+
+	if count == 0 {
+		a.xattrs = nil
+		return nil
+	} else if count > xattrListMax/2 { // Имя в списке с нулем не короче 2 байт
+		return ErrXattrCount(count)
+	}
+
+	a.xattrs = make(map[string][]byte, count)
+	for range count {
+		if name, err = readString(r); err != nil {
+			return err
+		}
+
+		if err = filesystem.BinaryRead(r, &length); err != nil {
+			return err
+		} else if length > xattrSizeMax {
+			return ErrXattrLength(length)
+		}
+
+		value := make([]byte, length)
+		if _, err = io.ReadFull(r, value); err != nil {
+			return err
+		}
+		a.xattrs[name] = value
+	}
+
+	return nil
+}
+
+// Сериализует атрибуты в w
+func (a attrs) write(w io.Writer) (err error) {
+	if HasFlag(FlagMode) {
+		if err = filesystem.BinaryWrite(w, uint32(a.mode)); err != nil {
+			return err
+		}
+	}
+
+	if HasFlag(FlagOwner) {
+		if err = filesystem.BinaryWrite(w, a.uid); err != nil {
+			return err
+		}
+		if err = filesystem.BinaryWrite(w, a.gid); err != nil {
+			return err
+		}
+		if err = writeString(w, a.uname); err != nil {
+			return err
+		}
+		if err = writeString(w, a.gname); err != nil {
+			return err
+		}
+	}
+
+	if HasFlag(FlagXattr) {
+		return a.writeXattrs(w)
+	}
+
+	return nil
+}
+
+// Сериализует расширенные атрибуты в w, упорядоченные по имени
+func (a attrs) writeXattrs(w io.Writer) (err error) {
+	if err = filesystem.BinaryWrite(w, uint16(len(a.xattrs))); err != nil {
+		return err
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(a.xattrs)) {
+		if err = writeString(w, name); err != nil {
+			return err
+		}
+
+		value := a.xattrs[name]
+		if err = filesystem.BinaryWrite(w, uint32(len(value))); err != nil {
+			return err
+		}
+
+		if err = filesystem.BinaryWrite(w, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Восстанавливает расширенные атрибуты элемента по пути path
+func (a attrs) restoreXattrs(path string) error {
+	for _, name := range slices.Sorted(maps.Keys(a.xattrs)) {
+		if err := setxattr(path, name, a.xattrs[name]); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// Восстанавливает права доступа к элементу по пути path
+func (a attrs) restoreMode(path string) error {
+	if !HasFlag(FlagMode) {
+		return nil
+	}
+
+	return os.Chmod(path, a.mode&permBits&^a.untrustedBits(path))
+}
+
+// Возвращает биты setuid, setgid и sticky, которые нельзя
+// восстановить элементу по пути path: его владелец или
+// группа не совпадают с архивными, то есть владелец не
+// восстановлен с '-chown' и распаковывающий пользователь
+// им не является. Иначе архив мог бы выдать файл setuid
+// от имени распаковывающего пользователя.
+func (a attrs) untrustedBits(path string) os.FileMode {
+	if !HasFlag(FlagOwner) {
+		return specialBits
+	}
+
+	uid, gid, ok := ownerIds(path)
+	if !ok {
+		return specialBits
+	}
+
+	var bits os.FileMode
+	localUid, localGid := a.localIds()
+	if uid != localUid {
+		bits |= os.ModeSetuid | os.ModeSticky
+	}
+	if gid != localGid {
+		bits |= os.ModeSetgid
+	}
+
+	return bits
+}
+
+// Кэш локальных идентификаторов пользователей и групп
+// по их именам, -1 -- имя в системе не найдено
+var (
+	userIds  = map[string]int{}
+	groupIds = map[string]int{}
+)
+
+// Восстанавливает владельца и группу элемента по пути path
+func (a attrs) restoreOwner(path string) error {
+	if !HasFlag(FlagOwner) {
+		return nil
+	}
+
+	uid, gid := a.localIds()
+	return os.Lchown(path, uid, gid)
+}
+
+// Возвращает локальные идентификаторы владельца и группы
+// элемента. Сначала ищет пользователя и группу по именам,
+// если таких нет, то использует числовые идентификаторы.
+func (a attrs) localIds() (uid, gid int) {
+	uid = lookupId(userIds, a.uname, a.uid, func(name string) (string, error) {
+		u, err := user.Lookup(name)
+		if err != nil {
+			return "", err
+		}
+		return u.Uid, nil
+	})
+
+	gid = lookupId(groupIds, a.gname, a.gid, func(name string) (string, error) {
+		g, err := user.LookupGroup(name)
+		if err != nil {
+			return "", err
+		}
+		return g.Gid, nil
+	})
+
+	return uid, gid
+}
+
+// Возвращает локальный идентификатор по имени name
+// с помощью lookup. Если имя не найдено, то
+// возвращает идентификатор id из архива.
+func lookupId(cache map[string]int, name string, id uint32, lookup func(string) (string, error)) int {
+	if name == "" {
+		return int(id)
+	}
+
+	localId, ok := cache[name]
+	if !ok {
+		localId = -1
+		if s, err := lookup(name); err == nil {
+			if n, err := strconv.Atoi(s); err == nil {
+				localId = n
+			}
+		}
+		cache[name] = localId
+	}
+
+	if localId < 0 {
+		return int(id)
+	}
+	return localId
+}
+
+// Дериализует строку из r
+func readString(r io.Reader) (_ string, err error) {
+	var length uint16
+
+	if err = filesystem.BinaryRead(r, &length); err != nil {
+		return "", err
+	}
+
+	buf := make([]byte, length)
+	if _, err = io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+
+	return string(buf), nil
+}
+
+// Сериализует строку s в w. Длина строки
+// записывается в 2 байтах.
+func writeString(w io.Writer, s string) (err error) {
+	if len(s) > math.MaxUint16 {
+		return ErrLongString(len(s))
+	}
+
+	if err = filesystem.BinaryWrite(w, uint16(len(s))); err != nil {
+		return err
+	}
+
+	return filesystem.BinaryWrite(w, []byte(s))
+}
@@ -2,10 +2,12 @@ package header
 
 import (
 	"archiver/filesystem"
+	"encoding/binary"
 	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
@@ -17,6 +19,56 @@ type PathProvider interface {
 type timeAttr struct {
 	atim time.Time // Последнее время доступа к элементу
 	mtim time.Time // Последнее время измения элемента
+	ctim time.Time // Последнее время изменения метаданных
+	btim time.Time // Время создания элемента
+}
+
+// Устанавливает время изменения метаданных и время создания
+func (t *timeAttr) SetChangeBirthTimes(ctim, btim time.Time) {
+	t.ctim, t.btim = ctim, btim
+}
+
+// Дериализует время из r. С флагом [FlagNanoTime]
+// время хранится с точностью до наносекунды.
+func readTime(r io.Reader) (_ time.Time, err error) {
+	var (
+		sec  int64
+		nsec uint32
+	)
+
+	if err = filesystem.BinaryRead(r, &sec); err != nil {
+		return time.Time{}, err
+	}
+
+	if HasFlag(FlagNanoTime) {
+		if err = filesystem.BinaryRead(r, &nsec); err != nil {
+			return time.Time{}, err
+		}
+	}
+
+	return time.Unix(sec, int64(nsec)), nil
+}
+
+// Сериализует время t в w
+func writeTime(w io.Writer, t time.Time) (err error) {
+	if err = filesystem.BinaryWrite(w, t.Unix()); err != nil {
+		return err
+	}
+
+	if HasFlag(FlagNanoTime) {
+		return filesystem.BinaryWrite(w, uint32(t.Nanosecond()))
+	}
+
+	return nil
+}
+
+// Форматирует время для вывода статистики
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+
+	return t.Format(dateFormat)
 }
 
 type basePaths struct {
@@ -27,16 +79,46 @@ type basePaths struct {
 func (b basePaths) PathOnDisk() string { return b.pathOnDisk }
 func (b basePaths) PathInArc() string  { return b.pathInArc }
 
-// Дериализует путь из r
+// Максимальная длина пути: PATH_MAX в Linux
+// без завершающего нулевого байта
+const MaxPathLen = 4095
+
+// Максимальная длина пути в архивах без [FlagLongPath]
+const legacyMaxPathLen = 1023
+
+// Возвращает максимальную длину пути в текущем архиве
+func maxPathLen() int {
+	if HasFlag(FlagLongPath) {
+		return MaxPathLen
+	}
+
+	return legacyMaxPathLen
+}
+
+// Дериализует путь из r. С флагом [FlagLongPath]
+// длина пути записана как беззнаковый varint.
 func readPath(r io.Reader) (_ string, err error) {
-	var length int16
+	var (
+		length    int64
+		maxLength = int64(maxPathLen())
+	)
 
-	if err = filesystem.BinaryRead(r, &length); err != nil {
-		return "", err
+	if HasFlag(FlagLongPath) {
+		var ulength uint64
+		if ulength, err = readUvarint(r); err != nil {
+			return "", err
+		}
+		length = int64(min(ulength, MaxPathLen+1))
+	} else {
+		var length16 int16
+		if err = filesystem.BinaryRead(r, &length16); err != nil {
+			return "", err
+		}
+		length = int64(length16)
 	}
 
-	if length < 1 || length > 1023 {
-		return "", ErrPathLength(int64(length))
+	if length < 1 || length > maxLength {
+		return "", ErrPathLength(length)
 	}
 
 	pathBytes := make([]byte, length)
@@ -47,13 +129,35 @@ func readPath(r io.Reader) (_ string, err error) {
 	return string(pathBytes), nil
 }
 
+// Десериализует путь элемента из r. Путь распаковывается
+// внутрь директории распаковки, поэтому пути, выходящие
+// за ее пределы, отклоняются. Ведущий разделитель
+// отбрасывается, как и при записи пути в архив.
+func readLocalPath(r io.Reader) (string, error) {
+	path, err := readPath(r)
+	if err != nil {
+		return "", err
+	}
+
+	if !filepath.IsLocal(filepath.FromSlash(strings.TrimLeft(path, "/"))) {
+		return "", ErrUnsafePath(path)
+	}
+
+	return path, nil
+}
+
 // Сериализует путь path в w
 func writePath(w io.Writer, path string) (err error) {
 	// Пишем длину строки имени файла или директории
-	if err = filesystem.BinaryWrite(w, int16(len(path))); err != nil {
+	if HasFlag(FlagLongPath) {
+		err = filesystem.BinaryWrite(w, binary.AppendUvarint(nil, uint64(len(path))))
+	} else {
+		err = filesystem.BinaryWrite(w, int16(len(path)))
+	}
+	if err != nil {
 		return err
 	}
-	log.Println("arc.header.writePath: Записана длина пути:", int16(len(path)))
+	log.Println("arc.header.writePath: Записана длина пути:", len(path))
 
 	// Пишем имя файла или директории
 	if err = filesystem.BinaryWrite(w, []byte(path)); err != nil {
@@ -64,54 +168,85 @@ func writePath(w io.Writer, path string) (err error) {
 	return nil
 }
 
+// Дериализует беззнаковый varint из r
+func readUvarint(r io.Reader) (uint64, error) {
+	var (
+		b     [1]byte
+		value uint64
+	)
+
+	for shift := 0; shift < 64; shift += 7 {
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return 0, err
+		}
+
+		value |= uint64(b[0]&0x7f) << shift
+		if b[0] < 0x80 {
+			return value, nil
+		}
+	}
+
+	return 0, ErrVarint
+}
+
 type Base struct {
 	basePaths
 	timeAttr
+	attrs
 }
 
 // Создает новый [header.Base]
 func NewBase(pathOnDisk string, atim, mtim time.Time) (*Base, error) {
-	if len(pathOnDisk) > 1023 {
-		return nil, ErrLongPath(pathOnDisk)
+	if len(pathOnDisk) > MaxPathLen {
+		return nil, ErrLongPath(pathOnDisk, MaxPathLen)
 	}
 
 	pathInArc := filesystem.Clean(pathOnDisk)
 
 	return &Base{
-		basePaths{pathOnDisk, pathInArc},
-		timeAttr{atim, mtim},
+		basePaths: basePaths{pathOnDisk, pathInArc},
+		timeAttr:  timeAttr{atim: atim, mtim: mtim},
 	}, nil
 }
 
 // Десериализует в себя данные из r
 func (b *Base) Read(r io.Reader) error {
 	var (
-		err      error
-		path     string
-		unixMtim int64
-		unixAtim int64
+		err  error
+		path string
+		mtim time.Time
+		atim time.Time
 	)
 
 	// Читаем имя файла
-	if path, err = readPath(r); err != nil {
+	if path, err = readLocalPath(r); err != nil {
 		return err
 	}
 
 	// Читаем время модификации
-	if err = filesystem.BinaryRead(r, &unixMtim); err != nil {
+	if mtim, err = readTime(r); err != nil {
 		return err
 	}
 
 	// Читаем время доступа
-	if err = filesystem.BinaryRead(r, &unixAtim); err != nil {
+	if atim, err = readTime(r); err != nil {
 		return err
 	}
 
-	mtim, atim := time.Unix(unixMtim, 0), time.Unix(unixAtim, 0)
-	newBase, _ := NewBase(path, mtim, atim)
+	newBase, _ := NewBase(path, atim, mtim)
 	*b = *newBase
 
-	return err
+	if HasFlag(FlagNanoTime) {
+		// Читаем время изменения метаданных и время создания
+		if b.ctim, err = readTime(r); err != nil {
+			return err
+		}
+		if b.btim, err = readTime(r); err != nil {
+			return err
+		}
+	}
+
+	return b.attrs.read(r)
 }
 
 // Сериализует данные полей в писатель w
@@ -121,21 +256,45 @@ func (b *Base) Write(w io.Writer) (err error) {
 		return err
 	}
 
-	atime, mtime := b.atim.Unix(), b.mtim.Unix()
-
 	// Пишем время модификации
-	if err = filesystem.BinaryWrite(w, mtime); err != nil {
+	if err = writeTime(w, b.mtim); err != nil {
 		return err
 	}
 
 	// Пишем имя время доступа
-	if err = filesystem.BinaryWrite(w, atime); err != nil {
+	if err = writeTime(w, b.atim); err != nil {
 		return err
 	}
 
-	return nil
+	if HasFlag(FlagNanoTime) {
+		// Пишем время изменения метаданных и время создания
+		if err = writeTime(w, b.ctim); err != nil {
+			return err
+		}
+		if err = writeTime(w, b.btim); err != nil {
+			return err
+		}
+	}
+
+	return b.attrs.write(w)
+}
+
+// Восстанавливает владельца и группу элемента
+func (b Base) RestoreOwner(outDir string) error {
+	return b.restoreOwner(filepath.Join(outDir, b.pathOnDisk))
+}
+
+// Восстанавливает расширенные атрибуты элемента
+func (b Base) RestoreXattrs(outDir string) error {
+	return b.restoreXattrs(filepath.Join(outDir, b.pathOnDisk))
+}
+
+// Восстанавливает права доступа к элементу
+func (b Base) RestoreMode(outDir string) error {
+	return b.restoreMode(filepath.Join(outDir, b.pathOnDisk))
 }
 
+// Восстанавливает временные метки элемента
 func (b Base) RestoreTime(outDir string) error {
 	outDir = filepath.Join(outDir, b.pathOnDisk)
 	if err := os.Chtimes(outDir, b.atim, b.mtim); err != nil {
@@ -0,0 +1,83 @@
+package header
+
+import (
+	"archiver/filesystem"
+	"crypto/sha256"
+	"io"
+)
+
+// Максимальное количество фрагментов в записи файла
+const maxChunks = 1 << 24
+
+// Хеш фрагмента данных
+type ChunkHash [sha256.Size]byte
+
+// Фрагмент данных файла при дедупликации
+type Chunk struct {
+	Hash   ChunkHash // SHA-256 данных фрагмента
+	Length uint32    // Длина фрагмента
+	// Фрагмент хранится в данных этого файла,
+	// иначе он ссылается на ранее записанный
+	Stored bool
+}
+
+// Возвращает фрагменты файла. Пустой срез означает,
+// что данные файла записаны без дедупликации.
+func (fi FileItem) Chunks() []Chunk { return fi.chunks }
+
+// Устанавливает фрагменты файла
+func (fi *FileItem) SetChunks(chunks []Chunk) { fi.chunks = chunks }
+
+// Возвращает положение в файле фрагментов, хранящихся
+// в его данных. Соседние фрагменты объединяются.
+func (fi FileItem) StoredRegions() (regions []SparseRegion) {
+	var offset int64
+
+	for _, ch := range fi.chunks {
+		if ch.Stored {
+			if n := len(regions); n > 0 &&
+				regions[n-1].Offset+regions[n-1].Length == offset {
+				regions[n-1].Length += int64(ch.Length)
+			} else {
+				regions = append(regions, SparseRegion{
+					Offset: offset, Length: int64(ch.Length),
+				})
+			}
+		}
+		offset += int64(ch.Length)
+	}
+
+	return regions
+}
+
+// Десериализует фрагменты файла из r
+func (fi *FileItem) readChunks(r io.Reader) (err error) {
+	var count uint32
+
+	if err = filesystem.BinaryRead(r, &count); err != nil {
+		return err
+	}
+
+	if count == 0 {
+		fi.chunks = nil
+		return nil
+	} else if count > maxChunks {
+		return ErrChunkCount(count)
+	}
+
+	fi.chunks = make([]Chunk, count)
+	return filesystem.BinaryRead(r, fi.chunks)
+}
+
+// Сериализует фрагменты файла в w
+func (fi FileItem) writeChunks(w io.Writer) (err error) {
+	if err = filesystem.BinaryWrite(w, uint32(len(fi.chunks))); err != nil {
+		return err
+	}
+
+	if len(fi.chunks) == 0 {
+		return nil
+	}
+
+	return filesystem.BinaryWrite(w, fi.chunks)
+}
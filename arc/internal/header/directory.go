@@ -1,22 +1,54 @@
 package header
 
-import "fmt"
+import (
+	"archiver/filesystem"
+	"fmt"
+	"io"
+	"path/filepath"
+)
 
 // Описание директории
 type DirItem struct {
-	basePaths
+	Base
 }
 
 // Создает заголовок директории [header.DirItem]
-func NewDirItem(pathInArc string) *DirItem {
-	return &DirItem{basePaths{pathInArc, pathInArc}}
+func NewDirItem(base *Base) *DirItem {
+	return &DirItem{Base: *base}
+}
+
+// Десериализует заголовок директории из r
+func (di *DirItem) Read(r io.Reader) error {
+	return di.Base.Read(r)
+}
+
+// Сериализует заголовок директории в w
+func (di *DirItem) Write(w io.Writer) (err error) {
+	if err = filesystem.BinaryWrite(w, Directory); err != nil {
+		return err
+	}
+
+	return di.Base.Write(w)
+}
+
+// Создает директорию со всем путем к ней
+func (di DirItem) RestorePath(outDir string) error {
+	return filesystem.CreatePath(filepath.Join(outDir, di.pathOnDisk))
 }
 
 // Реализация fmt.Stringer
 func (di DirItem) String() string {
 	filename := prefix(di.pathInArc, maxInArcWidth)
 
+	if di.mtim.IsZero() { // Директория без собственной записи
+		return fmt.Sprintf(
+			"%-*s", maxInArcWidth, filename,
+		)
+	}
+
 	return fmt.Sprintf(
-		"%-*s", maxInArcWidth, filename,
+		"%-*s %37s %10s%-2s%s %19s %19s",
+		maxInArcWidth, filename, "", di.modeString(), di.xattrMark(),
+		formatTime(di.mtim), formatTime(di.ctim), formatTime(di.btim),
 	)
 }
@@ -2,6 +2,7 @@ package header
 
 import (
 	"fmt"
+	"math"
 	"path/filepath"
 )
 
@@ -10,10 +11,48 @@ var (
 		return fmt.Errorf("некорректная длина (%d) пути элемента", length)
 	}
 
-	ErrLongPath = func(path string) error {
+	ErrLongPath = func(path string, maxLength int) error {
 		return fmt.Errorf(
-			"длина пути к '%s' первышает максимально допустимую (1023)",
-			filepath.Base(path),
+			"длина пути к '%s' превышает максимально допустимую (%d)",
+			filepath.Base(path), maxLength,
 		)
 	}
+
+	ErrLongString = func(length int) error {
+		return fmt.Errorf(
+			"длина строки (%d) превышает максимально допустимую (%d)",
+			length, math.MaxUint16,
+		)
+	}
+
+	ErrUnsafePath = func(path string) error {
+		return fmt.Errorf("путь '%s' выходит за пределы директории распаковки", path)
+	}
+
+	ErrIndexLength = func(count int64) error {
+		return fmt.Errorf("некорректное количество (%d) элементов индекса", count)
+	}
+
+	ErrIndexType = fmt.Errorf("неизвестный тип элемента индекса")
+
+	ErrVarint = fmt.Errorf("некорректное число переменной длины")
+
+	ErrSolidKind = func(kind SolidKind) error {
+		return fmt.Errorf("некорректный вид (%d) записи solid-группы", kind)
+	}
+
+	ErrChunkCount = func(count uint32) error {
+		return fmt.Errorf("некорректное количество (%d) фрагментов файла", count)
+	}
+
+	ErrXattrCount = func(count uint16) error {
+		return fmt.Errorf("некорректное количество (%d) расширенных атрибутов", count)
+	}
+
+	ErrXattrLength = func(length uint32) error {
+		return fmt.Errorf("некорректная длина (%d) значения расширенного атрибута", length)
+	}
+
+	ErrSpecialUnsupported = fmt.Errorf("специальные файлы не поддерживаются платформой")
+	ErrXattrUnsupported   = fmt.Errorf("расширенные атрибуты не поддерживаются платформой")
 )
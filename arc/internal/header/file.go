@@ -1,6 +1,7 @@
 package header
 
 import (
+	c "archiver/compressor"
 	"archiver/filesystem"
 	"fmt"
 	"io"
@@ -14,6 +15,11 @@ type FileItem struct {
 	ucSize, cSize Size
 	crc           uint32
 	damaged       bool
+	ct            c.Type  // Тип компрессора данных файла
+	cl            c.Level // Уровень сжатия данных файла
+	sparse        []SparseRegion
+	solid         SolidRef
+	chunks        []Chunk
 }
 
 // Возвращает размер данных в несжатом виде
@@ -28,6 +34,12 @@ func (fi FileItem) CRC() uint32 { return fi.crc }
 // Возвращает флаг наличия повреждении
 func (fi FileItem) IsDamaged() bool { return fi.damaged }
 
+// Возвращает тип компрессора данных файла
+func (fi FileItem) CompType() c.Type { return fi.ct }
+
+// Возвращает уровень сжатия данных файла
+func (fi FileItem) CompLevel() c.Level { return fi.cl }
+
 // Устанавливает размер данных в несжатом виде
 func (fi *FileItem) SetUcSize(size Size) { fi.ucSize = size }
 
@@ -40,6 +52,9 @@ func (fi *FileItem) SetCRC(crc uint32) { fi.crc = crc }
 // Устанавливает флаг наличия повреждении
 func (fi *FileItem) SetDamaged(damaged bool) { fi.damaged = damaged }
 
+// Устанавливает тип компрессора и уровень сжатия данных файла
+func (fi *FileItem) SetCodec(ct c.Type, cl c.Level) { fi.ct, fi.cl = ct, cl }
+
 // Создает заголовок файла [header.FileItem]
 func NewFileItem(base *Base, ucSize Size) *FileItem {
 	return &FileItem{Base: *base, ucSize: ucSize}
@@ -56,6 +71,37 @@ func (fi *FileItem) Read(r io.Reader) (err error) {
 		return err
 	}
 
+	if !HasFlag(FlagEntryCodec) { // Компрессор общий для архива
+		fi.ct, fi.cl = arcCt, c.DefaultCompression
+		return nil
+	}
+
+	// Читаем тип компрессора и уровень сжатия
+	var level int8
+	if err = filesystem.BinaryRead(r, &(fi.ct)); err != nil {
+		return err
+	}
+	if err = filesystem.BinaryRead(r, &level); err != nil {
+		return err
+	}
+	fi.cl = c.Level(level)
+
+	if HasFlag(FlagSparse) {
+		if err = fi.readSparse(r); err != nil {
+			return err
+		}
+	}
+
+	if HasFlag(FlagSolid) {
+		if err = fi.readSolid(r); err != nil {
+			return err
+		}
+	}
+
+	if HasFlag(FlagDedup) {
+		return fi.readChunks(r)
+	}
+
 	return nil
 }
 
@@ -72,6 +118,34 @@ func (fi *FileItem) Write(w io.Writer) (err error) {
 		return err
 	}
 
+	if !HasFlag(FlagEntryCodec) { // Компрессор общий для архива
+		return nil
+	}
+
+	// Пишем тип компрессора и уровень сжатия
+	if err = filesystem.BinaryWrite(w, fi.ct); err != nil {
+		return err
+	}
+	if err = filesystem.BinaryWrite(w, int8(fi.cl)); err != nil {
+		return err
+	}
+
+	if HasFlag(FlagSparse) {
+		if err = fi.writeSparse(w); err != nil {
+			return err
+		}
+	}
+
+	if HasFlag(FlagSolid) {
+		if err = fi.writeSolid(w); err != nil {
+			return err
+		}
+	}
+
+	if HasFlag(FlagDedup) {
+		return fi.writeChunks(w)
+	}
+
 	return nil
 }
 
@@ -96,7 +170,9 @@ func (fi FileItem) String() string {
 		ratio = 0
 	}
 
+	mode := fi.modeString()
 	mtime := fi.mtim.Format(dateFormat)
+	ctime, btime := formatTime(fi.ctim), formatTime(fi.btim)
 	crc := func() string {
 		if fi.crc != 0 {
 			return fmt.Sprintf("%8X", fi.crc)
@@ -106,8 +182,8 @@ func (fi FileItem) String() string {
 	}()
 
 	return fmt.Sprintf(
-		"%-*s %11s %11s %7.2f  %s %s",
-		maxInArcWidth, path, fi.ucSize,
-		fi.cSize, ratio, mtime, crc,
+		"%-*s %11s %11s %7.2f %5s %10s%-2s%s %19s %19s %8s",
+		maxInArcWidth, path, fi.ucSize, fi.cSize, ratio, fi.ct,
+		mode, fi.xattrMark(), mtime, ctime, btime, crc,
 	)
 }
@@ -0,0 +1,45 @@
+package header
+
+import c "archiver/compressor"
+
+// Флаги возможностей формата архива
+type Flags uint32
+
+const (
+	FlagIndex      Flags = 1 << iota // В конце архива записан индекс
+	FlagEntryCodec                   // Записи файлов хранят свой компрессор
+	FlagMode                         // Записи хранят права доступа
+	FlagOwner                        // Записи хранят владельца и группу
+	FlagDirectory                    // Директории записаны отдельными записями
+	FlagNanoTime                     // Время хранится с точностью до наносекунды
+	FlagHardLink                     // Жесткие ссылки записаны отдельными записями
+	FlagSpecial                      // Каналы и устройства записаны отдельными записями
+	FlagXattr                        // Записи хранят расширенные атрибуты
+	FlagLongPath                     // Длина пути записана как varint
+	FlagSparse                       // Записи файлов хранят карту областей данных
+	FlagDict                         // После заголовка архива записан словарь
+	FlagLzw                          // После словаря записаны параметры LZW
+	FlagSolid                        // Записи файлов хранят положение в solid-группе
+	FlagDedup                        // Записи файлов хранят ссылки на фрагменты данных
+)
+
+// Флаги, которые понимает эта сборка
+const SupportedFlags = FlagIndex | FlagEntryCodec | FlagMode |
+	FlagOwner | FlagDirectory | FlagNanoTime | FlagHardLink | FlagSpecial |
+	FlagXattr | FlagLongPath | FlagSparse | FlagDict | FlagLzw | FlagSolid | FlagDedup
+
+var (
+	flags Flags  // Флаги текущего архива
+	arcCt c.Type // Тип компрессора текущего архива
+)
+
+// Устанавливает флаги и тип компрессора текущего архива
+func SetFormat(f Flags, ct c.Type) {
+	flags, arcCt = f, ct
+}
+
+// Возвращает флаги текущего архива
+func CurrentFlags() Flags { return flags }
+
+// Проверяет наличие флага f у текущего архива
+func HasFlag(f Flags) bool { return flags&f == f }
@@ -0,0 +1,90 @@
+package header
+
+import (
+	"archiver/filesystem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Описание жесткой ссылки на файл, уже записанный в архив
+type LinkItem struct {
+	basePaths
+}
+
+// Создает заголовок жесткой ссылки [header.LinkItem].
+// target -- путь в архиве к файлу с данными.
+func NewLinkItem(link, target string) *LinkItem {
+	return &LinkItem{
+		basePaths{pathOnDisk: target, pathInArc: filesystem.Clean(link)},
+	}
+}
+
+// Создает жесткую ссылку на уже распакованный файл.
+// Если создать ссылку не удалось, то копирует файл.
+func (li LinkItem) RestorePath(outDir string) error {
+	var (
+		link   = filepath.Join(outDir, li.pathInArc)
+		target = filepath.Join(outDir, li.pathOnDisk)
+	)
+
+	if err := filesystem.CreatePath(filepath.Dir(link)); err != nil {
+		return err
+	}
+
+	if err := os.Remove(link); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if err := os.Link(target, link); err == nil {
+		return nil
+	}
+
+	return filesystem.CopyFile(target, link)
+}
+
+// Реализация fmt.Stringer
+func (li LinkItem) String() string {
+	filename := prefix(li.pathInArc, maxInArcWidth)
+	target := prefix(li.pathOnDisk, maxOnDiskWidth)
+
+	return fmt.Sprintf(
+		"%-*s => %s", maxInArcWidth,
+		filename, target,
+	)
+}
+
+// Десериализует в себя данные из r
+func (li *LinkItem) Read(r io.Reader) (err error) {
+	var link, target string
+
+	if link, err = readLocalPath(r); err != nil {
+		return err
+	}
+
+	// Файл ссылки тоже должен быть внутри директории распаковки
+	if target, err = readLocalPath(r); err != nil {
+		return err
+	}
+
+	*li = *NewLinkItem(link, target)
+
+	return nil
+}
+
+// Сериализует данные полей в писатель w
+func (li *LinkItem) Write(w io.Writer) (err error) {
+	if err = filesystem.BinaryWrite(w, HardLink); err != nil {
+		return err
+	}
+
+	// Пишем путь к ссылке
+	if err = writePath(w, li.pathInArc); err != nil {
+		return err
+	}
+
+	// Пишем путь к файлу с данными
+	return writePath(w, li.pathOnDisk)
+}
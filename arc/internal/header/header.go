@@ -1,9 +1,11 @@
 package header
 
 import (
+	"archiver/filesystem"
 	"fmt"
 	"math"
 	"strings"
+	"time"
 )
 
 // Максимальная ширина имени файла
@@ -18,6 +20,11 @@ type HeaderType byte
 const (
 	Symlink HeaderType = iota
 	File
+	Index // Индекс архива, после него записей нет
+	Directory
+	HardLink
+	Fifo   // Именованный канал
+	Device // Символьное или блочное устройство
 )
 
 type Header interface {
@@ -86,12 +93,90 @@ func DropDups(headers []Header) []Header {
 	return uniq
 }
 
+// Сообщает, что элемент cur не изменился по сравнению
+// с записанным в архив old: совпадают тип, время
+// модификации и размер файла, у ссылок -- путь к цели
+func Unchanged(old, cur Header) bool {
+	switch o := old.(type) {
+	case *FileItem:
+		c, ok := cur.(*FileItem)
+		return ok && o.ucSize == c.ucSize && sameTime(o.mtim, c.mtim)
+	case *DirItem:
+		c, ok := cur.(*DirItem)
+		return ok && sameTime(o.mtim, c.mtim)
+	case *FifoItem:
+		c, ok := cur.(*FifoItem)
+		return ok && sameTime(o.mtim, c.mtim)
+	case *DeviceItem:
+		c, ok := cur.(*DeviceItem)
+		return ok && sameTime(o.mtim, c.mtim)
+	case *SymItem:
+		c, ok := cur.(*SymItem)
+		return ok && o.pathOnDisk == c.pathOnDisk
+	case *LinkItem:
+		c, ok := cur.(*LinkItem)
+		return ok && o.pathOnDisk == c.pathOnDisk
+	}
+
+	return false
+}
+
+// Заменяет префикс old пути элемента h в архиве на new.
+// У жесткой ссылки заменяется и путь к файлу с данными.
+// Возвращает true, если изменился какой-либо из путей.
+func RenamePrefix(h Header, old, new string) (renamed bool, err error) {
+	var paths []*string // Пути, в которых заменяется префикс
+
+	switch e := h.(type) {
+	case *FileItem:
+		paths = []*string{&e.pathInArc, &e.pathOnDisk}
+	case *DirItem:
+		paths = []*string{&e.pathInArc, &e.pathOnDisk}
+	case *FifoItem:
+		paths = []*string{&e.pathInArc, &e.pathOnDisk}
+	case *DeviceItem:
+		paths = []*string{&e.pathInArc, &e.pathOnDisk}
+	case *SymItem: // Цель ссылки не меняется
+		paths = []*string{&e.pathInArc}
+	case *LinkItem:
+		paths = []*string{&e.pathInArc, &e.pathOnDisk}
+	}
+
+	for _, p := range paths {
+		// Путь символической ссылки может быть абсолютным
+		if cur := filesystem.Clean(*p); cur == old {
+			*p, renamed = new, true
+		} else if rest, ok := strings.CutPrefix(cur, old+"/"); ok {
+			*p, renamed = new+"/"+rest, true
+		} else {
+			continue
+		}
+
+		// Архивы без [FlagLongPath] хранят короткие пути
+		if len(*p) > maxPathLen() {
+			return false, ErrLongPath(*p, maxPathLen())
+		}
+	}
+
+	return renamed, nil
+}
+
+// Сравнивает время с точностью, с которой
+// оно хранится в текущем архиве
+func sameTime(a, b time.Time) bool {
+	if HasFlag(FlagNanoTime) {
+		return a.Equal(b)
+	}
+
+	return a.Unix() == b.Unix()
+}
+
 // Печатает заголовок статистики
 func PrintStatHeader() {
 	fmt.Printf( // Заголовок
-		"%-*s %11s %11s %7s  %19s %8s\n",
-		maxInArcWidth, "Имя файла", "Размер",
-		"Сжатый", "%", "Время модификации", "CRC32",
+		"%-*s %11s %11s %7s %5s %10s  %19s %19s %19s %8s\n",
+		maxInArcWidth, "Имя файла", "Размер", "Сжатый", "%", "Метод",
+		"Права", "Время модификации", "Время изменения", "Время создания", "CRC32",
 	)
 }
 
@@ -0,0 +1,80 @@
+package header
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenamePrefixPathLen(t *testing.T) {
+	t.Cleanup(func() { SetFormat(0, 0) })
+
+	newName := strings.Repeat("a", legacyMaxPathLen+1)
+	for _, tc := range []struct {
+		flags    Flags
+		maxLen   int
+		expected bool // Ожидается ошибка
+	}{
+		{SupportedFlags &^ FlagLongPath, legacyMaxPathLen, true},
+		{SupportedFlags, MaxPathLen, false},
+	} {
+		SetFormat(tc.flags, 0)
+
+		base, err := NewBase("dir", time.Now(), time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = RenamePrefix(NewDirItem(base), "dir", newName)
+		if !tc.expected && err != nil {
+			t.Errorf("Expected no error got %v", err)
+		} else if tc.expected && (err == nil ||
+			!strings.Contains(err.Error(), strconv.Itoa(tc.maxLen))) {
+			t.Errorf("Expected error with limit %d got %v", tc.maxLen, err)
+		}
+	}
+}
+
+func TestReadXattrsLimits(t *testing.T) {
+	for _, tc := range []struct {
+		data     []byte
+		expected error
+	}{
+		{
+			binary.LittleEndian.AppendUint16(nil, xattrListMax/2+1),
+			ErrXattrCount(xattrListMax/2 + 1),
+		},
+		{ // Один атрибут с именем "a"
+			binary.LittleEndian.AppendUint32(
+				[]byte{1, 0, 1, 0, 'a'}, xattrSizeMax+1,
+			),
+			ErrXattrLength(xattrSizeMax + 1),
+		},
+	} {
+		var a attrs
+		err := a.readXattrs(bytes.NewReader(tc.data))
+		if err == nil || err.Error() != tc.expected.Error() {
+			t.Errorf("Expected %q got %v", tc.expected, err)
+		}
+	}
+}
+
+func TestWriteStringLength(t *testing.T) {
+	var buf bytes.Buffer
+
+	s := strings.Repeat("a", math.MaxUint16)
+	if err := writeString(&buf, s); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := readString(&buf); err != nil || got != s {
+		t.Errorf("Expected %d bytes got %d (%v)", len(s), len(got), err)
+	}
+
+	if err := writeString(&buf, s+"a"); err == nil {
+		t.Error("Expected error on string longer than 65535 bytes")
+	}
+}
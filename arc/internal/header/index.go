@@ -0,0 +1,207 @@
+package header
+
+import (
+	"archiver/filesystem"
+	"io"
+)
+
+const (
+	// Сигнатура завершающего блока архива
+	footerMagic uint32 = 0x58444E49
+	// Длина завершающего блока: смещение индекса и сигнатура
+	FooterLen int64 = 12
+)
+
+// Элемент индекса архива
+type IndexEntry struct {
+	Offset int64  // Смещение записи элемента от начала архива
+	Header Header // Заголовок элемента
+}
+
+// Оставляет из записей с одинаковым путем в архиве
+// последнюю, порядок оставшихся записей сохраняется
+func LatestEntries(entries []IndexEntry) []IndexEntry {
+	var (
+		last   = make(map[string]int, len(entries))
+		latest = make([]IndexEntry, 0, len(entries))
+	)
+
+	for i, e := range entries {
+		last[e.Header.PathInArc()] = i
+	}
+
+	for i, e := range entries {
+		if last[e.Header.PathInArc()] == i {
+			latest = append(latest, e)
+		}
+	}
+
+	return latest
+}
+
+// Сериализует индекс архива entries в w
+func WriteIndex(w io.Writer, entries []IndexEntry) (err error) {
+	if err = filesystem.BinaryWrite(w, Index); err != nil {
+		return err
+	}
+
+	// Пишем количество элементов индекса
+	if err = filesystem.BinaryWrite(w, int64(len(entries))); err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		// Пишем смещение записи элемента
+		if err = filesystem.BinaryWrite(w, e.Offset); err != nil {
+			return err
+		}
+
+		switch h := e.Header.(type) {
+		case *FileItem:
+			if err = h.Write(w); err != nil {
+				return err
+			}
+
+			// Пишем размер сжатых данных и контрольную сумму
+			if err = filesystem.BinaryWrite(w, h.cSize); err != nil {
+				return err
+			}
+			if err = filesystem.BinaryWrite(w, h.crc); err != nil {
+				return err
+			}
+		case *SymItem:
+			if err = h.Write(w); err != nil {
+				return err
+			}
+		case *DirItem:
+			if err = h.Write(w); err != nil {
+				return err
+			}
+		case *LinkItem:
+			if err = h.Write(w); err != nil {
+				return err
+			}
+		case *FifoItem:
+			if err = h.Write(w); err != nil {
+				return err
+			}
+		case *DeviceItem:
+			if err = h.Write(w); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Десериализует индекс архива из r. Ожидается,
+// что тип заголовка [Index] уже прочитан.
+func ReadIndex(r io.Reader) (entries []IndexEntry, err error) {
+	var (
+		count  int64
+		offset int64
+		typ    HeaderType
+	)
+
+	// Читаем количество элементов индекса
+	if err = filesystem.BinaryRead(r, &count); err != nil {
+		return nil, err
+	}
+	if count < 0 {
+		return nil, ErrIndexLength(count)
+	}
+
+	for i := int64(0); i < count; i++ {
+		if err = filesystem.BinaryRead(r, &offset); err != nil {
+			return nil, err
+		}
+		if err = filesystem.BinaryRead(r, &typ); err != nil {
+			return nil, err
+		}
+
+		var h Header
+		switch typ {
+		case File:
+			fi := &FileItem{}
+			if err = fi.Read(r); err != nil {
+				return nil, err
+			}
+
+			// Читаем размер сжатых данных и контрольную сумму
+			if err = filesystem.BinaryRead(r, &fi.cSize); err != nil {
+				return nil, err
+			}
+			if err = filesystem.BinaryRead(r, &fi.crc); err != nil {
+				return nil, err
+			}
+			h = fi
+		case Symlink:
+			si := &SymItem{}
+			if err = si.Read(r); err != nil {
+				return nil, err
+			}
+			h = si
+		case Directory:
+			di := &DirItem{}
+			if err = di.Read(r); err != nil {
+				return nil, err
+			}
+			h = di
+		case HardLink:
+			li := &LinkItem{}
+			if err = li.Read(r); err != nil {
+				return nil, err
+			}
+			h = li
+		case Fifo:
+			fi := &FifoItem{}
+			if err = fi.Read(r); err != nil {
+				return nil, err
+			}
+			h = fi
+		case Device:
+			di := &DeviceItem{}
+			if err = di.Read(r); err != nil {
+				return nil, err
+			}
+			h = di
+		default:
+			return nil, ErrIndexType
+		}
+
+		entries = append(entries, IndexEntry{offset, h})
+	}
+
+	return entries, nil
+}
+
+// Сериализует завершающий блок архива, указывающий
+// на смещение индекса indexOffset
+func WriteFooter(w io.Writer, indexOffset int64) (err error) {
+	if err = filesystem.BinaryWrite(w, indexOffset); err != nil {
+		return err
+	}
+
+	return filesystem.BinaryWrite(w, footerMagic)
+}
+
+// Читает завершающий блок архива и возвращает
+// смещение индекса. Если завершающего блока нет,
+// то ok равен false.
+func ReadFooter(r io.ReadSeeker) (indexOffset int64, ok bool, err error) {
+	var magic uint32
+
+	if _, err = r.Seek(-FooterLen, io.SeekEnd); err != nil {
+		return 0, false, nil // Архив короче завершающего блока
+	}
+
+	if err = filesystem.BinaryRead(r, &indexOffset); err != nil {
+		return 0, false, err
+	}
+	if err = filesystem.BinaryRead(r, &magic); err != nil {
+		return 0, false, err
+	}
+
+	return indexOffset, magic == footerMagic, nil
+}
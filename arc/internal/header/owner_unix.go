@@ -0,0 +1,21 @@
+//go:build !windows
+// +build !windows
+
+package header
+
+import (
+	"os"
+	"syscall"
+)
+
+// Возвращает идентификаторы владельца и группы
+// элемента по пути path, не переходя по ссылке
+func ownerIds(path string) (uid, gid int, ok bool) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	stat := info.Sys().(*syscall.Stat_t)
+	return int(stat.Uid), int(stat.Gid), true
+}
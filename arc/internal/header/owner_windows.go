@@ -0,0 +1,9 @@
+//go:build windows
+// +build windows
+
+package header
+
+// Владелец в виде числовых идентификаторов не определяется
+func ownerIds(string) (uid, gid int, ok bool) {
+	return 0, 0, false
+}
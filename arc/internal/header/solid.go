@@ -0,0 +1,68 @@
+package header
+
+import (
+	"archiver/filesystem"
+	"io"
+)
+
+// Вид записи файла по отношению к solid-группе
+type SolidKind byte
+
+const (
+	SolidNone   SolidKind = iota // Данные файла сжаты отдельно
+	SolidHead                    // За записью следуют данные всей группы
+	SolidMember                  // Данные файла лежат в потоке группы
+)
+
+// Положение данных файла в общем потоке solid-группы
+type SolidRef struct {
+	Kind   SolidKind
+	Group  int64 // Смещение записи первого файла группы от начала архива
+	Offset int64 // Смещение данных файла в распакованном потоке группы
+}
+
+// Возвращает положение данных файла в solid-группе
+func (fi FileItem) Solid() SolidRef { return fi.solid }
+
+// Устанавливает положение данных файла в solid-группе
+func (fi *FileItem) SetSolid(ref SolidRef) { fi.solid = ref }
+
+// Сообщает, следуют ли за записью файла сжатые данные
+func (fi FileItem) HasData() bool { return fi.solid.Kind != SolidMember }
+
+// Десериализует положение в solid-группе из r
+func (fi *FileItem) readSolid(r io.Reader) (err error) {
+	if err = filesystem.BinaryRead(r, &fi.solid.Kind); err != nil {
+		return err
+	}
+
+	if fi.solid.Kind == SolidNone {
+		fi.solid = SolidRef{}
+		return nil
+	} else if fi.solid.Kind > SolidMember {
+		return ErrSolidKind(fi.solid.Kind)
+	}
+
+	if err = filesystem.BinaryRead(r, &fi.solid.Group); err != nil {
+		return err
+	}
+
+	return filesystem.BinaryRead(r, &fi.solid.Offset)
+}
+
+// Сериализует положение в solid-группе в w
+func (fi FileItem) writeSolid(w io.Writer) (err error) {
+	if err = filesystem.BinaryWrite(w, fi.solid.Kind); err != nil {
+		return err
+	}
+
+	if fi.solid.Kind == SolidNone {
+		return nil
+	}
+
+	if err = filesystem.BinaryWrite(w, fi.solid.Group); err != nil {
+		return err
+	}
+
+	return filesystem.BinaryWrite(w, fi.solid.Offset)
+}
@@ -0,0 +1,50 @@
+package header
+
+import (
+	"archiver/filesystem"
+	"io"
+)
+
+// Область данных разреженного файла
+type SparseRegion struct {
+	Offset int64 // Смещение области от начала файла
+	Length int64 // Длина области
+}
+
+// Возвращает области данных разреженного файла. Пустой
+// срез означает, что файл не разреженный. Файл из одних
+// дыр описывается областью нулевой длины в конце файла.
+func (fi FileItem) Sparse() []SparseRegion { return fi.sparse }
+
+// Устанавливает области данных разреженного файла
+func (fi *FileItem) SetSparse(regions []SparseRegion) { fi.sparse = regions }
+
+// Десериализует карту областей данных из r
+func (fi *FileItem) readSparse(r io.Reader) (err error) {
+	var count uint32
+
+	if err = filesystem.BinaryRead(r, &count); err != nil {
+		return err
+	}
+
+	if count == 0 {
+		fi.sparse = nil
+		return nil
+	}
+
+	fi.sparse = make([]SparseRegion, count)
+	return filesystem.BinaryRead(r, fi.sparse)
+}
+
+// Сериализует карту областей данных в w
+func (fi FileItem) writeSparse(w io.Writer) (err error) {
+	if err = filesystem.BinaryWrite(w, uint32(len(fi.sparse))); err != nil {
+		return err
+	}
+
+	if len(fi.sparse) == 0 {
+		return nil
+	}
+
+	return filesystem.BinaryWrite(w, fi.sparse)
+}
@@ -0,0 +1,143 @@
+package header
+
+import (
+	"archiver/filesystem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Описание именованного канала
+type FifoItem struct {
+	Base
+}
+
+// Создает заголовок именованного канала [header.FifoItem]
+func NewFifoItem(base *Base) *FifoItem {
+	return &FifoItem{Base: *base}
+}
+
+// Десериализует заголовок именованного канала из r
+func (fi *FifoItem) Read(r io.Reader) error {
+	return fi.Base.Read(r)
+}
+
+// Сериализует заголовок именованного канала в w
+func (fi *FifoItem) Write(w io.Writer) (err error) {
+	if err = filesystem.BinaryWrite(w, Fifo); err != nil {
+		return err
+	}
+
+	return fi.Base.Write(w)
+}
+
+// Создает именованный канал, заменяя существующий элемент
+func (fi FifoItem) RestorePath(outDir string) error {
+	path, err := prepareSpecial(outDir, fi.pathOnDisk)
+	if err != nil {
+		return err
+	}
+
+	return mkfifo(path, uint32(fi.mode&os.ModePerm))
+}
+
+// Реализация fmt.Stringer
+func (fi FifoItem) String() string {
+	return specialString(fi.Base, "")
+}
+
+// Описание символьного или блочного устройства
+type DeviceItem struct {
+	Base
+	char  bool   // Символьное устройство
+	major uint32 // Старший номер устройства
+	minor uint32 // Младший номер устройства
+}
+
+// Создает заголовок устройства [header.DeviceItem]
+func NewDeviceItem(base *Base, char bool, major, minor uint32) *DeviceItem {
+	return &DeviceItem{Base: *base, char: char, major: major, minor: minor}
+}
+
+// Десериализует заголовок устройства из r
+func (di *DeviceItem) Read(r io.Reader) (err error) {
+	if err = di.Base.Read(r); err != nil {
+		return err
+	}
+
+	if err = filesystem.BinaryRead(r, &di.char); err != nil {
+		return err
+	}
+
+	if err = filesystem.BinaryRead(r, &di.major); err != nil {
+		return err
+	}
+
+	return filesystem.BinaryRead(r, &di.minor)
+}
+
+// Сериализует заголовок устройства в w
+func (di *DeviceItem) Write(w io.Writer) (err error) {
+	if err = filesystem.BinaryWrite(w, Device); err != nil {
+		return err
+	}
+
+	if err = di.Base.Write(w); err != nil {
+		return err
+	}
+
+	if err = filesystem.BinaryWrite(w, di.char); err != nil {
+		return err
+	}
+
+	if err = filesystem.BinaryWrite(w, di.major); err != nil {
+		return err
+	}
+
+	return filesystem.BinaryWrite(w, di.minor)
+}
+
+// Создает узел устройства, заменяя существующий элемент
+func (di DeviceItem) RestorePath(outDir string) error {
+	path, err := prepareSpecial(outDir, di.pathOnDisk)
+	if err != nil {
+		return err
+	}
+
+	return mknod(path, di.char, uint32(di.mode&os.ModePerm), di.major, di.minor)
+}
+
+// Реализация fmt.Stringer
+func (di DeviceItem) String() string {
+	return specialString(di.Base, fmt.Sprintf("%d,%d", di.major, di.minor))
+}
+
+// Создает путь к специальному файлу и удаляет
+// существующий элемент, возвращает полный путь
+func prepareSpecial(outDir, pathOnDisk string) (string, error) {
+	path := filepath.Join(outDir, pathOnDisk)
+
+	if err := filesystem.CreatePath(filepath.Dir(path)); err != nil {
+		return "", err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+
+	return path, nil
+}
+
+// Форматирует строку статистики специального файла,
+// в колонке размера выводится номер устройства
+func specialString(b Base, dev string) string {
+	filename := prefix(b.pathInArc, maxInArcWidth)
+
+	return fmt.Sprintf(
+		"%-*s %11s %25s %10s%-2s%s %19s %19s",
+		maxInArcWidth, filename, dev, "", b.modeString(), b.xattrMark(),
+		formatTime(b.mtim), formatTime(b.ctim), formatTime(b.btim),
+	)
+}
@@ -0,0 +1,23 @@
+//go:build darwin
+// +build darwin
+
+package header
+
+import "syscall"
+
+// Создает именованный канал
+func mkfifo(path string, perm uint32) error {
+	return syscall.Mkfifo(path, perm)
+}
+
+// Создает узел устройства
+func mknod(path string, char bool, perm, major, minor uint32) error {
+	mode := perm | syscall.S_IFBLK
+	if char {
+		mode = perm | syscall.S_IFCHR
+	}
+
+	dev := (major&0xff)<<24 | minor&0xffffff
+
+	return syscall.Mknod(path, mode, int(dev))
+}
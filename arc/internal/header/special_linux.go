@@ -0,0 +1,24 @@
+//go:build linux
+// +build linux
+
+package header
+
+import "syscall"
+
+// Создает именованный канал
+func mkfifo(path string, perm uint32) error {
+	return syscall.Mkfifo(path, perm)
+}
+
+// Создает узел устройства с номером в кодировке glibc
+func mknod(path string, char bool, perm, major, minor uint32) error {
+	mode := perm | syscall.S_IFBLK
+	if char {
+		mode = perm | syscall.S_IFCHR
+	}
+
+	dev := uint64(minor&0xff) | uint64(major&0xfff)<<8 |
+		uint64(minor&^0xff)<<12 | uint64(major&^0xfff)<<32
+
+	return syscall.Mknod(path, mode, int(dev))
+}
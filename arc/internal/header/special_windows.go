@@ -0,0 +1,14 @@
+//go:build windows
+// +build windows
+
+package header
+
+// Именованные каналы в файловой системе не создаются
+func mkfifo(string, uint32) error {
+	return ErrSpecialUnsupported
+}
+
+// Узлы устройств в файловой системе не создаются
+func mknod(string, bool, uint32, uint32, uint32) error {
+	return ErrSpecialUnsupported
+}
@@ -12,12 +12,13 @@ import (
 // Описание символической ссылки
 type SymItem struct {
 	basePaths
+	attrs
 }
 
 // Создает заголовок символической ссылки [header.SymItem]
 func NewSymItem(symlink, target string) *SymItem {
 	return &SymItem{
-		basePaths{pathOnDisk: target, pathInArc: symlink},
+		basePaths: basePaths{pathOnDisk: target, pathInArc: filesystem.Clean(symlink)},
 	}
 }
 
@@ -37,6 +38,16 @@ func (si SymItem) RestorePath(outDir string) error {
 	return nil
 }
 
+// Восстанавливает владельца и группу символьной ссылки
+func (si SymItem) RestoreOwner(outDir string) error {
+	return si.restoreOwner(filepath.Join(outDir, si.pathInArc))
+}
+
+// Восстанавливает расширенные атрибуты символьной ссылки
+func (si SymItem) RestoreXattrs(outDir string) error {
+	return si.restoreXattrs(filepath.Join(outDir, si.pathInArc))
+}
+
 // Реализация fmt.Stringer
 func (si SymItem) String() string {
 	filename := prefix(si.pathInArc, maxInArcWidth)
@@ -61,7 +72,8 @@ func (si *SymItem) Read(r io.Reader) error {
 		return err
 	}
 
-	// Читаем размер строки symlink
+	// Читаем размер строки symlink. Прежде путь ссылки
+	// хранился как есть и нормализуется при создании.
 	if symlink, err = readPath(r); err != nil {
 		return err
 	}
@@ -69,7 +81,7 @@ func (si *SymItem) Read(r io.Reader) error {
 	newSym := NewSymItem(symlink, target)
 	*si = *newSym
 
-	return err
+	return si.attrs.read(r)
 }
 
 // Сериализует данные полей в писатель w
@@ -85,5 +97,5 @@ func (si *SymItem) Write(w io.Writer) (err error) {
 		return err
 	}
 
-	return nil
+	return si.attrs.write(w)
 }
@@ -0,0 +1,12 @@
+//go:build linux
+// +build linux
+
+package header
+
+import "golang.org/x/sys/unix"
+
+// Устанавливает расширенный атрибут name файла path,
+// не переходя по символической ссылке
+func setxattr(path, name string, value []byte) error {
+	return unix.Lsetxattr(path, name, value, 0)
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+// +build !linux
+
+package header
+
+// Расширенные атрибуты не устанавливаются, в пакете
+// syscall этой платформы нет системных вызовов для них
+func setxattr(string, string, []byte) error {
+	return ErrXattrUnsupported
+}
@@ -0,0 +1,304 @@
+package arc
+
+import (
+	"archiver/arc/internal/compress"
+	"archiver/arc/internal/decompress"
+	"archiver/arc/internal/header"
+	c "archiver/compressor"
+	"archiver/errtype"
+	"archiver/params"
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+)
+
+// Объединяемый архив
+type mergeSource struct {
+	path    string
+	file    *os.File
+	arc     Arc               // Параметры из заголовка архива
+	records []compress.Record // Записи в порядке следования
+	latest  map[int64]bool    // Смещения последних записей путей
+}
+
+// Запись объединяемого архива
+type mergeRecord struct {
+	src *mergeSource
+	compress.Record
+}
+
+// Объединяет архивы paths в новый архив без распаковки
+// данных. Из последних записей одинакового пути в разных
+// архивах остается одна по правилу dup, записи нового
+// архива упорядочены по пути. Новый архив пишется во
+// временный файл, поэтому может заменить один из paths.
+func (arc Arc) Merge(paths []string, dup params.DupPolicy) error {
+	sources := make([]*mergeSource, 0, len(paths))
+	defer func() {
+		for _, src := range sources {
+			src.file.Close()
+		}
+	}()
+
+	for _, path := range paths {
+		src, err := openSource(path)
+		if err != nil {
+			return errtype.ErrRuntime(errtype.Join(ErrMergeArc, err))
+		}
+		sources = append(sources, src)
+	}
+
+	if err := arc.mergeParams(sources); err != nil {
+		return errtype.ErrRuntime(errtype.Join(ErrMergeArc, err))
+	}
+
+	records, err := mergeRecords(sources, dup)
+	if err != nil {
+		return errtype.ErrRuntime(errtype.Join(ErrMergeArc, err))
+	}
+
+	arcFile, err := arc.writeArcHeader()
+	if err != nil {
+		return errtype.ErrRuntime(
+			errtype.Join(ErrMergeArc, ErrWriteArcHeaders, err),
+		)
+	}
+
+	cp := compress.NewCopier(arcFile, arc.headerLen)
+	for _, r := range records {
+		if err = cp.Copy(r.src.file, r.Record); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = cp.Finish(true)
+	}
+	if err != nil {
+		arc.closeRemove(arcFile)
+		return errtype.ErrRuntime(errtype.Join(ErrMergeArc, err))
+	}
+
+	if err = arc.replaceWithTmp(arcFile); err != nil {
+		arc.RemoveTmp()
+		return errtype.ErrRuntime(errtype.Join(ErrMergeArc, err))
+	}
+	fmt.Printf("Объединено архивов: %d, записей: %d\n", len(sources), len(records))
+
+	return nil
+}
+
+// Открывает объединяемый архив и читает его записи.
+// Записи файлов должны хранить свой компрессор.
+func openSource(path string) (*mergeSource, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errtype.Join(ErrOpenArc, err)
+	}
+
+	src := &mergeSource{path: path, file: file}
+	if err = src.read(); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return src, nil
+}
+
+// Читает заголовок и записи архива
+func (src *mergeSource) read() error {
+	if err := src.arc.readArcHeader(src.file, src.path); err != nil {
+		return err
+	}
+
+	if !header.HasFlag(header.FlagIndex | header.FlagEntryCodec |
+		header.FlagDict | header.FlagLzw) {
+		return ErrMergeFormat(src.path)
+	}
+
+	entries, err := decompress.ReadEntries(src.file, src.arc.headerLen)
+	if err != nil {
+		return errtype.Join(ErrReadHeaders, err)
+	}
+
+	end, err := decompress.RecordsEnd(src.file, src.arc.headerLen)
+	if err != nil {
+		return err
+	}
+
+	src.records = compress.Records(entries, end)
+	src.latest = make(map[int64]bool, len(entries))
+	for _, e := range header.LatestEntries(entries) {
+		src.latest[e.Offset] = true
+	}
+
+	return nil
+}
+
+// Выбирает параметры нового архива и проверяет совместимость
+// архивов sources: их форматы совпадают, а файлы, сжатые со
+// словарем или LZW, распаковываются с общими параметрами.
+// Тип компрессора берется из первого архива, записи файлов
+// хранят свой компрессор.
+func (arc *Arc) mergeParams(sources []*mergeSource) error {
+	var dictSet, lzwSet bool
+
+	arc.flags, arc.Ct = sources[0].arc.flags, sources[0].arc.Ct
+	arc.Dict, arc.Lzw = nil, c.DefaultLzwParams
+
+	for _, src := range sources {
+		if src.arc.flags != arc.flags {
+			return ErrMergeFormat(src.path)
+		}
+
+		var usesDict, usesLzw bool
+		for _, r := range src.records {
+			if fi, ok := r.Header.(*header.FileItem); ok {
+				usesDict = usesDict || c.SupportsDict(fi.CompType())
+				usesLzw = usesLzw || fi.CompType() == c.LempelZivWelch
+			}
+		}
+
+		// Данные, сжатые без словаря, распаковываются с любым словарем
+		if usesDict && len(src.arc.Dict) > 0 {
+			if !dictSet {
+				arc.Dict, dictSet = src.arc.Dict, true
+			} else if !bytes.Equal(arc.Dict, src.arc.Dict) {
+				return ErrMergeDict(src.path)
+			}
+		}
+
+		if usesLzw {
+			if !lzwSet {
+				arc.Lzw, lzwSet = src.arc.Lzw, true
+			} else if arc.Lzw != src.arc.Lzw {
+				return ErrMergeLzw(src.path)
+			}
+		}
+	}
+
+	arc.headerLen = arcHeaderLen + 4 + int64(len(arc.Dict)) + 2
+
+	return nil
+}
+
+// Выбирает записи нового архива и сортирует их по пути.
+// Записи, данные которых нужны оставшимся файлам, тоже
+// переносятся и скрываются выбранной записью того же пути.
+func mergeRecords(sources []*mergeSource, dup params.DupPolicy) ([]mergeRecord, error) {
+	winners := make(map[string]mergeRecord)
+
+	for _, src := range sources {
+		for _, r := range src.records {
+			if !src.latest[r.Offset] {
+				continue
+			}
+
+			path := r.Header.PathInArc()
+			prev, ok := winners[path]
+			switch {
+			case !ok || dup == params.DupLast:
+				winners[path] = mergeRecord{src, r}
+			case dup == params.DupError && !(isDirRecord(prev.Record) && isDirRecord(r)):
+				// Общие директории архивов не считаются повтором
+				return nil, ErrMergeDuplicate(path)
+			}
+		}
+	}
+
+	var kept, dropped []mergeRecord
+	for _, src := range sources {
+		for _, r := range src.records {
+			path := r.Header.PathInArc()
+			if w := winners[path]; w.src == src && w.Offset == r.Offset {
+				kept = append(kept, mergeRecord{src, r})
+				continue
+			}
+
+			dropped = append(dropped, mergeRecord{src, r})
+			if src.latest[r.Offset] && !isDirRecord(r) {
+				fmt.Printf("Пропущен %s из '%s'\n", path, src.path)
+			}
+		}
+	}
+
+	// Нужные записи идут раньше записей с тем же путем
+	records := append(neededRecords(kept, dropped), kept...)
+	slices.SortStableFunc(records, func(a, b mergeRecord) int {
+		pair := header.ByPathInArc{a.Header, b.Header}
+		if pair.Less(0, 1) {
+			return -1
+		} else if pair.Less(1, 0) {
+			return 1
+		}
+		return 0
+	})
+
+	return records, nil
+}
+
+// Возвращает записи dropped, данные которых нужны записям
+// kept: первые файлы solid-групп оставшихся файлов и файлы,
+// хранящие фрагменты оставшихся файлов
+func neededRecords(kept, dropped []mergeRecord) (needed []mergeRecord) {
+	type group struct {
+		src    *mergeSource
+		offset int64
+	}
+
+	var (
+		groups = map[group]bool{}            // Группы оставшихся файлов
+		chunks = map[header.ChunkHash]bool{} // Фрагменты без хранящей их записи
+		stored = func(fi *header.FileItem) { // Убирает хранимые файлом фрагменты
+			for _, ch := range fi.Chunks() {
+				if ch.Stored {
+					delete(chunks, ch.Hash)
+				}
+			}
+		}
+	)
+
+	for _, r := range kept {
+		if fi, ok := r.Header.(*header.FileItem); ok {
+			if ref := fi.Solid(); ref.Kind == header.SolidMember {
+				groups[group{r.src, ref.Group}] = true
+			}
+			for _, ch := range fi.Chunks() {
+				if !ch.Stored {
+					chunks[ch.Hash] = true
+				}
+			}
+		}
+	}
+
+	for _, r := range kept {
+		if fi, ok := r.Header.(*header.FileItem); ok {
+			stored(fi)
+		}
+	}
+
+	for _, r := range dropped {
+		fi, ok := r.Header.(*header.FileItem)
+		if !ok {
+			continue
+		}
+
+		need := fi.Solid().Kind == header.SolidHead && groups[group{r.src, r.Offset}]
+		for _, ch := range fi.Chunks() {
+			need = need || ch.Stored && chunks[ch.Hash]
+		}
+
+		if need {
+			stored(fi)
+			needed = append(needed, r)
+		}
+	}
+
+	return needed
+}
+
+// Сообщает, является ли запись r директорией
+func isDirRecord(r compress.Record) bool {
+	_, ok := r.Header.(*header.DirItem)
+	return ok
+}
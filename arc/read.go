@@ -0,0 +1,114 @@
+package arc
+
+import (
+	"archiver/arc/internal/generic"
+	"archiver/arc/internal/header"
+	c "archiver/compressor"
+	"archiver/errtype"
+	"archiver/filesystem"
+	"compress/lzw"
+	"io"
+)
+
+// Читает заголовок архива из r и устанавливает
+// тип компрессора и флаги формата.
+//
+// Заголовок версии 0 состоит из сигнатуры и типа
+// компрессора. В версионированном заголовке после
+// сигнатуры идут версия с признаком [versionMark],
+// флаги возможностей и тип компрессора.
+func (arc *Arc) readArcHeader(r io.Reader, name string) (err error) {
+	var magic uint16
+	if err = filesystem.BinaryRead(r, &magic); err != nil {
+		return errtype.Join(ErrReadMagic, err)
+	}
+	if magic != magicNumber {
+		return ErrNotArc(name)
+	}
+
+	var version byte
+	if err = filesystem.BinaryRead(r, &version); err != nil {
+		return errtype.Join(ErrReadArcHeader, err)
+	}
+
+	var (
+		compType byte
+		flags    header.Flags
+	)
+
+	if version&versionMark == 0 { // Версия 0
+		compType = version
+		arc.headerLen = legacyHeaderLen
+	} else {
+		if version &^= versionMark; version > formatVersion {
+			return ErrVersion(version)
+		}
+
+		if err = filesystem.BinaryRead(r, &flags); err != nil {
+			return errtype.Join(ErrReadArcHeader, err)
+		}
+		if unknown := flags &^ header.SupportedFlags; unknown != 0 {
+			return ErrUnsupportedFlags(uint32(unknown))
+		}
+
+		if err = filesystem.BinaryRead(r, &compType); err != nil {
+			return errtype.Join(ErrReadArcHeader, err)
+		}
+		arc.headerLen = arcHeaderLen
+	}
+
+	if c.Registered(c.Type(compType)) {
+		arc.Ct = c.Type(compType)
+	} else {
+		return ErrUnknownComp
+	}
+
+	arc.flags = flags
+	header.SetFormat(flags, arc.Ct)
+
+	arc.Dict = nil
+	if header.HasFlag(header.FlagDict) {
+		if arc.Dict, err = readDict(r); err != nil {
+			return errtype.Join(ErrReadDict, err)
+		}
+		arc.headerLen += 4 + int64(len(arc.Dict))
+	}
+	generic.SetDictionary(arc.Dict)
+
+	arc.Lzw = c.DefaultLzwParams
+	if header.HasFlag(header.FlagLzw) {
+		var lp [2]byte // Порядок бит и разрядность литералов
+		if _, err = io.ReadFull(r, lp[:]); err != nil {
+			return errtype.Join(ErrReadLzwParams, err)
+		}
+		arc.Lzw = c.LzwParams{Order: lzw.Order(lp[0]), LitWidth: lp[1]}
+		arc.headerLen += 2
+	}
+	if err = generic.SetLzwParams(arc.Lzw); err != nil {
+		return errtype.Join(ErrReadLzwParams, err)
+	}
+
+	return nil
+}
+
+// Читает предустановленный словарь архива. Пустой
+// словарь означает, что архив сжат без словаря.
+func readDict(r io.Reader) (dict []byte, err error) {
+	var size uint32
+	if err = filesystem.BinaryRead(r, &size); err != nil {
+		return nil, err
+	}
+
+	if size == 0 {
+		return nil, nil
+	} else if size > c.MaxDictSize {
+		return nil, ErrDictSize(size)
+	}
+
+	dict = make([]byte, size)
+	if _, err = io.ReadFull(r, dict); err != nil {
+		return nil, err
+	}
+
+	return dict, nil
+}
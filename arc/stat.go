@@ -3,6 +3,7 @@ package arc
 import (
 	"archiver/arc/internal/decompress"
 	"archiver/arc/internal/header"
+	c "archiver/compressor"
 	"archiver/errtype"
 	"fmt"
 	"os"
@@ -17,7 +18,7 @@ func (arc Arc) ViewStat() error {
 		)
 	}
 
-	headers, err := decompress.ReadHeaders(arcFile, arcHeaderLen)
+	headers, err := decompress.ReadHeaders(arcFile, arc.headerLen)
 	if err != nil {
 		return errtype.ErrRuntime(
 			errtype.Join(ErrReadHeaders, err),
@@ -25,19 +26,56 @@ func (arc Arc) ViewStat() error {
 	}
 
 	fmt.Printf("Тип компрессора: %s\n", arc.Ct)
+	if len(arc.Dict) > 0 {
+		fmt.Printf("Словарь: %d байт\n", len(arc.Dict))
+	}
+	if arc.Ct == c.LempelZivWelch {
+		fmt.Printf("Параметры LZW: %s\n", arc.Lzw)
+	}
 	header.PrintStatHeader()
 
-	var original, compressed header.Size
+	var (
+		original, compressed header.Size
+		stored               int // Файлы, сохраненные без сжатия
+		groups               int // Solid-группы
+		refs                 int // Ссылки на повторные фрагменты
+		refSize              header.Size
+	)
 	for _, h := range headers {
 		fmt.Println(h)
 
 		if fi, ok := h.(*header.FileItem); ok {
 			original += fi.UcSize()
 			compressed += fi.CSize()
+
+			if fi.CompType() == c.Nop {
+				stored++
+			}
+			if fi.Solid().Kind == header.SolidHead {
+				groups++
+			}
+			for _, ch := range fi.Chunks() {
+				if !ch.Stored {
+					refs++
+					refSize += header.Size(ch.Length)
+				}
+			}
 		}
 	}
 	header.PrintSummary(compressed, original)
 
+	if stored > 0 && arc.Ct != c.Nop {
+		fmt.Printf("Сохранено без сжатия: %d\n", stored)
+	}
+
+	if groups > 0 {
+		fmt.Printf("Solid-групп: %d\n", groups)
+	}
+
+	if refs > 0 {
+		fmt.Printf("Повторных фрагментов: %d (%s)\n", refs, refSize)
+	}
+
 	return nil
 }
 
@@ -50,7 +88,7 @@ func (arc Arc) ViewList() error {
 		)
 	}
 
-	headers, err := decompress.ReadHeaders(arcFile, arcHeaderLen)
+	headers, err := decompress.ReadHeaders(arcFile, arc.headerLen)
 	if err != nil {
 		return errtype.ErrRuntime(
 			errtype.Join(ErrReadHeaders, err),
@@ -60,6 +98,8 @@ func (arc Arc) ViewList() error {
 	for _, h := range headers {
 		if si, ok := h.(*header.SymItem); ok {
 			fmt.Println(si.PathInArc(), "->", si.PathOnDisk())
+		} else if li, ok := h.(*header.LinkItem); ok {
+			fmt.Println(li.PathInArc(), "=>", li.PathOnDisk())
 		} else {
 			fmt.Println(h.PathOnDisk())
 		}
@@ -0,0 +1,84 @@
+package arc
+
+import (
+	"archiver/arc/internal/compress"
+	"archiver/arc/internal/decompress"
+	"archiver/arc/internal/generic"
+	"archiver/arc/internal/header"
+	c "archiver/compressor"
+	"archiver/errtype"
+	"fmt"
+	"os"
+)
+
+// Перекодирует архив path в новый архив компрессором и
+// уровнем сжатия arc. Заголовки элементов, словарь и
+// параметры LZW переносятся как есть, а данные файлов
+// распаковываются и сразу сжимаются заново без записи
+// на диск. Новый архив пишется во временный файл,
+// поэтому может заменить исходный.
+func (arc Arc) Transcode(path string) error {
+	srcFile, err := os.Open(path)
+	if err != nil {
+		return errtype.ErrRuntime(errtype.Join(ErrTranscodeArc, ErrOpenArc, err))
+	}
+	defer srcFile.Close()
+
+	var src Arc
+	if err = src.readArcHeader(srcFile, path); err != nil {
+		return errtype.ErrRuntime(errtype.Join(ErrTranscodeArc, err))
+	}
+
+	entries, err := decompress.ReadEntries(srcFile, src.headerLen)
+	if err != nil {
+		return errtype.ErrRuntime(errtype.Join(ErrTranscodeArc, ErrReadHeaders, err))
+	}
+
+	end, err := decompress.RecordsEnd(srcFile, src.headerLen)
+	if err != nil {
+		return errtype.ErrRuntime(errtype.Join(ErrTranscodeArc, err))
+	}
+
+	// Словарь и параметры LZW пишутся всегда, остальные
+	// возможности формата берутся из исходного архива
+	arc.flags = src.flags | header.FlagDict | header.FlagLzw
+	arc.Dict, arc.Lzw = src.Dict, src.Lzw
+	arc.headerLen = arcHeaderLen + 4 + int64(len(arc.Dict)) + 2
+
+	arcFile, err := arc.writeArcHeader()
+	if err != nil {
+		return errtype.ErrRuntime(
+			errtype.Join(ErrTranscodeArc, ErrWriteArcHeaders, err),
+		)
+	}
+	generic.SetWriteBufSize((generic.BufferSize() * generic.Ncpu()) << 1)
+
+	cp := compress.NewCopier(arcFile, arc.headerLen)
+	for _, r := range compress.Records(entries, end) {
+		ct, cl := arc.Ct, arc.Cl
+		// Файлы, сохраненные без сжатия, не сжимаются и теперь
+		if fi, ok := r.Header.(*header.FileItem); ok && arc.AutoStore &&
+			header.HasFlag(header.FlagEntryCodec) && fi.CompType() == c.Nop {
+			ct, cl = c.Nop, c.NoCompression
+		}
+
+		if err = cp.Transcode(srcFile, r, ct, cl); err != nil {
+			break
+		}
+		fmt.Println(r.Header.PathInArc())
+	}
+	if err == nil {
+		err = cp.Finish(header.HasFlag(header.FlagIndex))
+	}
+	if err != nil {
+		arc.closeRemove(arcFile)
+		return errtype.ErrRuntime(errtype.Join(ErrTranscodeArc, err))
+	}
+
+	if err = arc.replaceWithTmp(arcFile); err != nil {
+		arc.RemoveTmp()
+		return errtype.ErrRuntime(errtype.Join(ErrTranscodeArc, err))
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package arc
+
+import (
+	"archiver/arc/internal/compress"
+	"archiver/arc/internal/decompress"
+	"archiver/arc/internal/generic"
+	"archiver/arc/internal/header"
+	c "archiver/compressor"
+	"archiver/errtype"
+	"archiver/params"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Открывает существующий архив для дополнения: читает
+// заголовок архива, проверяет совместимость параметров
+// сжатия и запоминает индекс, который будет перезаписан
+func (arc *Arc) openUpdate(p params.Params) error {
+	arcFile, err := os.Open(arc.arcPath)
+	if err != nil {
+		return errtype.Join(ErrOpenArc, err)
+	}
+	defer arcFile.Close()
+
+	if err = arc.readArcHeader(arcFile, arcFile.Name()); err != nil {
+		return err
+	}
+
+	// Записи файлов должны хранить свой компрессор
+	if !header.HasFlag(header.FlagIndex|header.FlagEntryCodec) ||
+		(p.Solid && !header.HasFlag(header.FlagSolid)) ||
+		(p.Dedup && !header.HasFlag(header.FlagDedup)) {
+		return ErrUpdateFormat
+	}
+
+	// Без сжатия файлы сохраняются в архиве любого типа
+	if p.Cl != c.NoCompression && p.Ct != arc.Ct {
+		return ErrUpdateCompType(arc.Ct)
+	}
+	arc.Ct = p.Ct
+
+	if arc.appendAt, err = decompress.RecordsEnd(arcFile, arc.headerLen); err != nil {
+		return errtype.Join(ErrUpdateArc, err)
+	}
+
+	arcFile.Seek(arc.appendAt, io.SeekStart)
+	if arc.tail, err = io.ReadAll(arcFile); err != nil {
+		return errtype.Join(ErrUpdateArc, err)
+	}
+	arc.update = true
+
+	return nil
+}
+
+// Дописывает в конец записей архива новые и измененные
+// элементы путей paths и пишет новый индекс. При ошибке
+// архиву возвращается прежний индекс.
+func (arc Arc) appendArc(paths []string) error {
+	arcFile, err := os.OpenFile(arc.arcPath, os.O_RDWR, 0644)
+	if err != nil {
+		return errtype.ErrCompress(errtype.Join(ErrOpenArc, err))
+	}
+
+	entries, err := decompress.ReadEntries(arcFile, arc.headerLen)
+	if err != nil {
+		arcFile.Close()
+		return errtype.ErrCompress(errtype.Join(ErrReadHeaders, err))
+	}
+
+	headers, err := compress.PrepareHeaders(paths)
+	if err != nil {
+		arcFile.Close()
+		return errtype.ErrCompress(err)
+	}
+
+	if headers = changedHeaders(headers, entries); len(headers) == 0 {
+		fmt.Println("Новых или измененных элементов нет")
+		return arcFile.Close()
+	}
+
+	if err = generic.InitCompressors(arc.Ct, arc.Cl); err != nil {
+		arcFile.Close()
+		return errtype.ErrCompress(
+			errtype.Join(ErrCompressorInit, err),
+		)
+	}
+
+	// Установка размера буфера записи
+	generic.SetWriteBufSize((generic.BufferSize() * generic.Ncpu()) << 1)
+
+	if err = arcFile.Truncate(arc.appendAt); err == nil {
+		_, err = arcFile.Seek(arc.appendAt, io.SeekStart)
+	}
+	if err == nil {
+		err = compress.AppendHeaders(arcFile, arc.appendAt, entries, headers, arc.RestoreParams)
+	}
+	if err != nil {
+		arc.rollback(arcFile)
+		return errtype.ErrCompress(errtype.Join(ErrUpdateArc, err))
+	}
+
+	return arcFile.Close()
+}
+
+// Оставляет из headers элементы, которых нет среди
+// записей entries или которые изменились
+func changedHeaders(headers []header.Header, entries []header.IndexEntry) []header.Header {
+	var (
+		latest  = make(map[string]header.Header, len(entries))
+		changed []header.Header
+	)
+
+	for _, e := range entries {
+		latest[e.Header.PathInArc()] = e.Header
+	}
+
+	for _, h := range headers {
+		if old, ok := latest[h.PathInArc()]; !ok || !header.Unchanged(old, h) {
+			changed = append(changed, h)
+		}
+	}
+
+	return changed
+}
+
+// Отбрасывает дописанные записи, возвращает архиву
+// прежний индекс и закрывает arcFile
+func (arc Arc) rollback(arcFile *os.File) {
+	arcFile.Truncate(arc.appendAt)
+	arcFile.WriteAt(arc.tail, arc.appendAt)
+	arcFile.Close()
+}
@@ -1,6 +1,7 @@
 package arc
 
 import (
+	"archiver/arc/internal/header"
 	"archiver/errtype"
 	"archiver/filesystem"
 	"os"
@@ -8,8 +9,13 @@ import (
 
 // Создает файл архива и пишет информацию об архиве
 func (arc Arc) writeArcHeader() (arcFile *os.File, err error) {
-	// Создаем файл
-	arcFile, err = os.Create(arc.arcPath)
+	// Создаем файл, переписываемый архив создается во
+	// временном файле, чтобы не испортить исходные архивы
+	path := arc.arcPath
+	if arc.edit {
+		path = arc.tmpPath()
+	}
+	arcFile, err = os.Create(path)
 	if err != nil {
 		return nil, errtype.Join(ErrCreateArc, err)
 	}
@@ -19,10 +25,35 @@ func (arc Arc) writeArcHeader() (arcFile *os.File, err error) {
 		return nil, errtype.Join(ErrWriteMagic, err)
 	}
 
+	// Пишем версию формата
+	if err = filesystem.BinaryWrite(arcFile, versionMark|formatVersion); err != nil {
+		return nil, errtype.Join(ErrWriteVersion, err)
+	}
+
+	// Пишем флаги возможностей формата
+	if err = filesystem.BinaryWrite(arcFile, arc.flags); err != nil {
+		return nil, errtype.Join(ErrWriteVersion, err)
+	}
+	header.SetFormat(arc.flags, arc.Ct)
+
 	// Пишем тип компрессора
 	if err = filesystem.BinaryWrite(arcFile, arc.Ct); err != nil {
 		return nil, errtype.Join(ErrWriteCompType, err)
 	}
 
+	// Пишем предустановленный словарь
+	if err = filesystem.BinaryWrite(arcFile, uint32(len(arc.Dict))); err != nil {
+		return nil, errtype.Join(ErrWriteDict, err)
+	}
+	if err = filesystem.BinaryWrite(arcFile, arc.Dict); err != nil {
+		return nil, errtype.Join(ErrWriteDict, err)
+	}
+
+	// Пишем параметры LZW
+	lp := []byte{byte(arc.Lzw.Order), arc.Lzw.LitWidth}
+	if err = filesystem.BinaryWrite(arcFile, lp); err != nil {
+		return nil, errtype.Join(ErrWriteLzw, err)
+	}
+
 	return arcFile, nil
 }
@@ -2,10 +2,11 @@ package compressor
 
 import (
 	"archiver/errtype"
+	"compress/bzip2"
 	"compress/flate"
 	"compress/gzip"
-	"compress/lzw"
 	"compress/zlib"
+	"fmt"
 	"io"
 )
 
@@ -16,11 +17,18 @@ const (
 	GZip
 	LempelZivWelch
 	ZLib
+	Flate // DEFLATE без заголовков и контрольных сумм
+	BZip2 // Только распаковка
+	LZ4
 )
 
 // Реализация fmt.Stringer
 func (ct Type) String() string {
-	return [...]string{"Nop", "GZip", "LZW", "ZLib"}[ct]
+	if c, ok := lookup(ct); ok {
+		return c.name
+	}
+
+	return fmt.Sprintf("Type(%d)", ct)
 }
 
 type Level int // Уровень сжатия
@@ -33,25 +41,97 @@ const (
 	BestCompression    Level = flate.BestCompression
 )
 
+// Уровни сжатия семейства DEFLATE
+var flateLevels = LevelRange{HuffmanOnly, BestCompression}
+
+// Уровень сжатия компрессоров, которые его не учитывают
+var noLevels = LevelRange{DefaultCompression, DefaultCompression}
+
+// Регистрирует встроенные компрессоры
+func init() {
+	Register(Nop, "Nop",
+		func(r io.Reader, _ []byte) (ReadCloseResetter, error) {
+			return &nopReader{io.NopCloser(r)}, nil
+		},
+		func(w io.Writer, _ Level, _ []byte) (WriteCloseResetter, error) {
+			return nopWriteCloser{Writer: w}, nil
+		},
+		LevelRange{NoCompression, NoCompression},
+	)
+
+	Register(GZip, "GZip",
+		func(r io.Reader, _ []byte) (ReadCloseResetter, error) {
+			return gzip.NewReader(r)
+		},
+		func(w io.Writer, l Level, _ []byte) (WriteCloseResetter, error) {
+			return gzip.NewWriterLevel(w, int(l))
+		},
+		flateLevels,
+	)
+
+	Register(LempelZivWelch, "LZW",
+		func(r io.Reader, _ []byte) (ReadCloseResetter, error) {
+			return newLzwReader(r), nil
+		},
+		func(w io.Writer, _ Level, _ []byte) (WriteCloseResetter, error) {
+			return newLzwWriter(w), nil
+		},
+		noLevels,
+	)
+
+	register(ZLib, "ZLib",
+		func(r io.Reader, dict []byte) (ReadCloseResetter, error) {
+			z, err := zlib.NewReaderDict(r, dict)
+			if err != nil {
+				return nil, err
+			}
+			return &zlibReader{z, dict}, nil
+		},
+		func(w io.Writer, l Level, dict []byte) (WriteCloseResetter, error) {
+			return zlib.NewWriterLevelDict(w, int(l), dict)
+		},
+		flateLevels, true,
+	)
+
+	register(Flate, "Flate",
+		func(r io.Reader, dict []byte) (ReadCloseResetter, error) {
+			return &flateReader{flate.NewReaderDict(r, dict), dict}, nil
+		},
+		func(w io.Writer, l Level, dict []byte) (WriteCloseResetter, error) {
+			return flate.NewWriterDict(w, int(l), dict)
+		},
+		flateLevels, true,
+	)
+
+	Register(BZip2, "BZip2",
+		func(r io.Reader, _ []byte) (ReadCloseResetter, error) {
+			return &bzip2Reader{bzip2.NewReader(r)}, nil
+		},
+		nil, // Только распаковка
+		noLevels,
+	)
+
+	Register(LZ4, "LZ4",
+		func(r io.Reader, _ []byte) (ReadCloseResetter, error) {
+			return newLZ4Reader(r), nil
+		},
+		func(w io.Writer, _ Level, _ []byte) (WriteCloseResetter, error) {
+			return newLZ4Writer(w), nil
+		},
+		noLevels,
+	)
+}
+
 // Дополнение интерфейса [io.ReadCloser] методом сброса
 type ReadCloseResetter interface {
 	io.ReadCloser
 	Reset(io.Reader) error
 }
 
-// Адаптер для [lzw.Reader]
-type lzwReader struct {
-	*lzw.Reader
-}
-
-func (lr *lzwReader) Reset(r io.Reader) error {
-	lr.Reader.Reset(r, lzw.MSB, 8)
-	return nil
-}
-
 // Адаптер для [zlib.reader]
 type zlibReader struct {
 	reader io.ReadCloser
+	dict   []byte // Предустановленный словарь
 }
 
 func (zr *zlibReader) Read(p []byte) (int, error) {
@@ -63,7 +143,29 @@ func (zr *zlibReader) Close() error {
 }
 
 func (zr *zlibReader) Reset(r io.Reader) error {
-	return zr.reader.(zlib.Resetter).Reset(r, nil)
+	return zr.reader.(zlib.Resetter).Reset(r, zr.dict)
+}
+
+// Адаптер для читателя [flate]
+type flateReader struct {
+	io.ReadCloser
+	dict []byte // Предустановленный словарь
+}
+
+func (fr *flateReader) Reset(r io.Reader) error {
+	return fr.ReadCloser.(flate.Resetter).Reset(r, fr.dict)
+}
+
+// Адаптер для читателя [bzip2]
+type bzip2Reader struct {
+	io.Reader
+}
+
+func (br *bzip2Reader) Close() error { return nil }
+
+func (br *bzip2Reader) Reset(r io.Reader) error {
+	br.Reader = bzip2.NewReader(r)
+	return nil
 }
 
 type Reader struct {
@@ -72,7 +174,14 @@ type Reader struct {
 
 // Возвращает нового читателя типа typ
 func NewReader(typ Type, r io.Reader) (*Reader, error) {
-	reader, err := newReader(typ, r)
+	return NewReaderDict(typ, r, nil)
+}
+
+// Возвращает нового читателя типа typ с предустановленным
+// словарем dict. Компрессоры без поддержки словаря его
+// не учитывают.
+func NewReaderDict(typ Type, r io.Reader, dict []byte) (*Reader, error) {
+	reader, err := newReader(typ, r, dict)
 	if err != nil {
 		if err == io.EOF {
 			return nil, err
@@ -85,23 +194,13 @@ func NewReader(typ Type, r io.Reader) (*Reader, error) {
 }
 
 // Выбирает читателя согласно typ
-func newReader(typ Type, r io.Reader) (ReadCloseResetter, error) {
-	switch typ {
-	case GZip:
-		return gzip.NewReader(r)
-	case LempelZivWelch:
-		return &lzwReader{lzw.NewReader(r, lzw.MSB, 8).(*lzw.Reader)}, nil
-	case ZLib:
-		z, err := zlib.NewReader(r)
-		if err != nil {
-			return nil, err
-		}
-		return &zlibReader{z}, nil
-	case Nop:
-		return &nopReader{io.NopCloser(r)}, nil
-	default:
+func newReader(typ Type, r io.Reader, dict []byte) (ReadCloseResetter, error) {
+	c, ok := lookup(typ)
+	if !ok {
 		return nil, ErrUnknownComp
 	}
+
+	return c.newReader(r, dict)
 }
 
 // Читает из внутреннего [Reader.reader] в p
@@ -125,22 +224,20 @@ type WriteCloseResetter interface {
 	Reset(io.Writer)
 }
 
-// Адаптер для [lzw.Writer]
-type lzwWriter struct {
-	*lzw.Writer
-}
-
-func (lw *lzwWriter) Reset(w io.Writer) {
-	lw.Writer.Reset(w, lzw.MSB, 8)
-}
-
 type Writer struct {
 	writer WriteCloseResetter
 }
 
 // Возвращает нового писателя типа typ
 func NewWriter(typ Type, w io.Writer, l Level) (*Writer, error) {
-	writer, err := newWriter(typ, w, l)
+	return NewWriterDict(typ, w, l, nil)
+}
+
+// Возвращает нового писателя типа typ с предустановленным
+// словарем dict. Компрессоры без поддержки словаря его
+// не учитывают.
+func NewWriterDict(typ Type, w io.Writer, l Level, dict []byte) (*Writer, error) {
+	writer, err := newWriter(typ, w, l, dict)
 	if err != nil {
 		if err == io.EOF {
 			return nil, err
@@ -152,19 +249,15 @@ func NewWriter(typ Type, w io.Writer, l Level) (*Writer, error) {
 }
 
 // Выбирает писателя согласно typ
-func newWriter(typ Type, w io.Writer, l Level) (WriteCloseResetter, error) {
-	switch typ {
-	case GZip:
-		return gzip.NewWriterLevel(w, int(l))
-	case LempelZivWelch:
-		return &lzwWriter{lzw.NewWriter(w, lzw.MSB, 8).(*lzw.Writer)}, nil
-	case ZLib:
-		return zlib.NewWriterLevel(w, int(l))
-	case Nop:
-		return nopWriteCloser{Writer: w}, nil
-	default:
+func newWriter(typ Type, w io.Writer, l Level, dict []byte) (WriteCloseResetter, error) {
+	c, ok := lookup(typ)
+	if !ok {
 		return nil, ErrUnknownComp
+	} else if c.newWriter == nil {
+		return nil, ErrReadOnlyComp
 	}
+
+	return c.newWriter(w, l, dict)
 }
 
 // Сжимает len(p) байт из p во внутренний writer
@@ -3,8 +3,10 @@ package compressor_test
 import (
 	"archiver/compressor"
 	"bytes"
+	"compress/lzw"
 	"crypto/md5"
 	"fmt"
+	"io"
 	"math/rand"
 	"slices"
 	"testing"
@@ -31,6 +33,278 @@ func TestZlib(t *testing.T) {
 	}
 }
 
+func TestFlate(t *testing.T) {
+	for cl := compressor.Level(-2); cl <= 9; cl++ {
+		runTest(t, compressor.Flate, cl)
+	}
+}
+
+func TestLzwParams(t *testing.T) {
+	t.Cleanup(func() { compressor.SetLzwParams(compressor.DefaultLzwParams) })
+
+	if err := compressor.SetLzwParams(compressor.LzwParams{Order: lzw.LSB, LitWidth: 9}); err == nil {
+		t.Error("Expected error on 9 bit literals")
+	}
+
+	if err := compressor.SetLzwParams(compressor.LzwParams{Order: lzw.LSB, LitWidth: 8}); err != nil {
+		t.Fatal(err)
+	}
+	runTest(t, compressor.LempelZivWelch, compressor.Level(-1))
+
+	data := bytes.Repeat([]byte("hello, lzw "), 64)
+	if err := compressor.SetLzwParams(compressor.LzwParams{Order: lzw.MSB, LitWidth: 7}); err != nil {
+		t.Fatal(err)
+	}
+
+	var compBuf, out bytes.Buffer
+	c, err := compressor.NewWriter(compressor.LempelZivWelch, &compBuf, compressor.Level(-1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Write(data)
+	if err = c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := compressor.NewReader(compressor.LempelZivWelch, &compBuf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = d.WriteTo(&out); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out.Bytes(), data) {
+		t.Errorf("Expected %q got %q", data, out.Bytes())
+	}
+}
+
+func TestLz4(t *testing.T) {
+	runTest(t, compressor.LZ4, compressor.Level(-1))
+
+	var (
+		rng        = rand.New(rand.NewSource(time.Now().Unix()))
+		random     = make([]byte, 256*1024)
+		multiBlock = bytes.Repeat([]byte("archiver lz4 block "), 200*1024)
+	)
+	rng.Read(random)
+
+	var (
+		compBuf bytes.Buffer
+		c       *compressor.Writer
+		d       *compressor.Reader
+		err     error
+	)
+	for _, tc := range []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"short", []byte("abc")},
+		{"random", random},
+		{"multi block", multiBlock},
+		{"empty after reset", nil},
+	} {
+		t.Log("Testing", compressor.LZ4, "compressor on", tc.name, "data")
+
+		// Писатель и читатель переиспользуются после сброса
+		compBuf.Reset()
+		if c == nil {
+			if c, err = compressor.NewWriter(compressor.LZ4, &compBuf, compressor.Level(-1)); err != nil {
+				t.Fatal(err)
+			}
+		} else {
+			c.Reset(&compBuf)
+		}
+		if _, err = c.Write(tc.data); err != nil {
+			t.Fatal(err)
+		}
+		if err = c.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		if d == nil {
+			if d, err = compressor.NewReader(compressor.LZ4, &compBuf); err != nil {
+				t.Fatal(err)
+			}
+		} else if err = d.Reset(&compBuf); err != nil {
+			t.Fatal(err)
+		}
+		var out bytes.Buffer
+		if _, err = d.WriteTo(&out); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(out.Bytes(), tc.data) {
+			t.Errorf("Expected %d bytes got %d", len(tc.data), out.Len())
+		}
+	}
+}
+
+func TestLz4Frame(t *testing.T) {
+	// printf 'archiver lz4, archiver lz4, archiver lz4\n' | lz4 -9 -c
+	compressed := []byte{
+		0x04, 0x22, 0x4d, 0x18, 0x64, 0x40, 0xa7, 0x18, 0x00, 0x00, 0x00, 0xef,
+		0x61, 0x72, 0x63, 0x68, 0x69, 0x76, 0x65, 0x72, 0x20, 0x6c, 0x7a, 0x34,
+		0x2c, 0x20, 0x0e, 0x00, 0x03, 0x50, 0x20, 0x6c, 0x7a, 0x34, 0x0a, 0x00,
+		0x00, 0x00, 0x00, 0xa3, 0xbc, 0x36, 0x83,
+	}
+	const expected = "archiver lz4, archiver lz4, archiver lz4\n"
+
+	d, err := compressor.NewReader(compressor.LZ4, bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if _, err = d.WriteTo(&out); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != expected {
+		t.Errorf("Expected %q got %q", expected, out.String())
+	}
+
+	// Пустой кадр писателя: заголовок и признак конца,
+	// lz4 -d распаковывает его без ошибок
+	emptyFrame := []byte{
+		0x04, 0x22, 0x4d, 0x18, 0x60, 0x60, 0x51, 0x00, 0x00, 0x00, 0x00,
+	}
+	var compBuf bytes.Buffer
+	c, err := compressor.NewWriter(compressor.LZ4, &compBuf, compressor.Level(-1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(compBuf.Bytes(), emptyFrame) {
+		t.Errorf("Expected % x got % x", emptyFrame, compBuf.Bytes())
+	}
+}
+
+func TestBzip2(t *testing.T) {
+	// printf 'archiver bzip2\n' | bzip2 -9
+	compressed := []byte{
+		0x42, 0x5a, 0x68, 0x39, 0x31, 0x41, 0x59, 0x26, 0x53, 0x59, 0x11, 0x3f,
+		0x8f, 0xac, 0x00, 0x00, 0x01, 0xd9, 0x80, 0x00, 0x10, 0x40, 0x00, 0x10,
+		0x00, 0x3a, 0x60, 0x51, 0x10, 0x20, 0x00, 0x22, 0x98, 0x01, 0x90, 0x80,
+		0x68, 0x01, 0x77, 0x59, 0xd8, 0x59, 0x04, 0x85, 0x3c, 0x2e, 0xe4, 0x8a,
+		0x70, 0xa1, 0x20, 0x22, 0x7f, 0x1f, 0x58,
+	}
+	const expected = "archiver bzip2\n"
+
+	if _, err := compressor.NewWriter(compressor.BZip2, nil, -1); err == nil {
+		t.Error("Expected error for bzip2 compressor")
+	}
+
+	d, err := compressor.NewReader(compressor.BZip2, bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range 2 { // Второй проход после сброса
+		decompBuf := bytes.NewBuffer(nil)
+		if _, err = d.WriteTo(decompBuf); err != nil {
+			t.Fatal(err)
+		}
+
+		if decompBuf.String() != expected {
+			t.Errorf("Expected %q got %q", expected, decompBuf.String())
+		}
+
+		if err = d.Reset(bytes.NewReader(compressed)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err = d.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDict(t *testing.T) {
+	var (
+		dict = []byte("Архиватор на базе встроенной библиотеки Golang")
+		data = bytes.Repeat([]byte("архиватор Golang "), 4)
+	)
+
+	for _, ct := range []compressor.Type{compressor.ZLib, compressor.Flate} {
+		t.Log("Testing", ct, "compressor with dictionary")
+		if !compressor.SupportsDict(ct) {
+			t.Fatalf("Expected %s to support dictionary", ct)
+		}
+
+		var plain, withDict bytes.Buffer
+		for _, tc := range []struct {
+			buf  *bytes.Buffer
+			dict []byte
+		}{{&plain, nil}, {&withDict, dict}} {
+			c, err := compressor.NewWriterDict(ct, tc.buf, compressor.Level(9), tc.dict)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c.Write(data)
+			if err = c.Close(); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if withDict.Len() >= plain.Len() {
+			t.Errorf("Expected less than %d bytes got %d", plain.Len(), withDict.Len())
+		}
+
+		d, err := compressor.NewReaderDict(ct, bytes.NewReader(withDict.Bytes()), dict)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for range 2 { // Повторное чтение после Reset
+			var out bytes.Buffer
+			if _, err = d.WriteTo(&out); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(out.Bytes(), data) {
+				t.Errorf("Expected %q got %q", data, out.Bytes())
+			}
+			if err = d.Reset(bytes.NewReader(withDict.Bytes())); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	const custom = compressor.Type(200)
+	t.Cleanup(func() { compressor.Unregister(custom) })
+
+	compressor.Register(custom, "Custom",
+		func(r io.Reader, dict []byte) (compressor.ReadCloseResetter, error) {
+			return compressor.NewReader(compressor.ZLib, r)
+		},
+		func(w io.Writer, l compressor.Level, dict []byte) (compressor.WriteCloseResetter, error) {
+			return compressor.NewWriter(compressor.ZLib, w, l)
+		},
+		compressor.LevelRange{Min: 1, Max: 9},
+	)
+
+	if ct, ok := compressor.Lookup("custom"); !ok || ct != custom {
+		t.Fatalf("Expected %d got %d", custom, ct)
+	}
+	if custom.String() != "Custom" {
+		t.Errorf("Expected Custom got %s", custom)
+	}
+	runTest(t, custom, compressor.Level(5))
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic on duplicate name")
+		}
+	}()
+	compressor.Register(201, "GZIP",
+		func(r io.Reader, dict []byte) (compressor.ReadCloseResetter, error) {
+			return compressor.NewReader(compressor.GZip, r)
+		},
+		nil, compressor.LevelRange{},
+	)
+}
+
 func runTest(t *testing.T, ct compressor.Type, cl compressor.Level) {
 	const dataSize = 12 * 1024 * 1024
 
@@ -46,7 +320,7 @@ func runTest(t *testing.T, ct compressor.Type, cl compressor.Level) {
 	)
 
 	switch ct {
-	case compressor.LempelZivWelch, compressor.Nop:
+	case compressor.LempelZivWelch, compressor.LZ4, compressor.Nop:
 		t.Log("Testing", ct, "compressor")
 	default:
 		t.Log("Testing", ct, "compressor with", cl, "level")
@@ -6,4 +6,10 @@ var (
 	ErrDecompCreate = fmt.Errorf("не могу создать новый декомпрессор")
 	ErrCompCreate   = fmt.Errorf("не могу создать новый компрессор")
 	ErrUnknownComp  = fmt.Errorf("неизвестный тип компрессора")
+	ErrReadOnlyComp = fmt.Errorf("компрессор поддерживает только распаковку")
+	ErrLZ4Header    = fmt.Errorf("некорректный заголовок кадра LZ4")
+	ErrLZ4Block     = fmt.Errorf("некорректный блок LZ4")
+	ErrLzwParams    = func(order, litWidth byte) error {
+		return fmt.Errorf("некорректные параметры LZW (порядок %d, литералы %d бит)", order, litWidth)
+	}
 )
@@ -0,0 +1,5 @@
+package compressor
+
+// Тесты регистрируют свои компрессоры и удаляют
+// их, чтобы проходить повторно при -count
+var Unregister = unregister
@@ -0,0 +1,502 @@
+package compressor
+
+import (
+	"encoding/binary"
+	"io"
+	"math/bits"
+)
+
+// Параметры формата кадра LZ4
+const (
+	lz4Magic        uint32 = 0x184D2204 // Сигнатура кадра
+	lz4Version      byte   = 0x40       // Версия формата 01 в битах 7-6
+	lz4BlockIndep   byte   = 0x20       // Блоки независимы друг от друга
+	lz4BlockCRC     byte   = 0x10       // У блоков есть контрольная сумма
+	lz4ContentSize  byte   = 0x08       // В заголовке есть размер содержимого
+	lz4ContentCRC   byte   = 0x04       // В конце кадра есть контрольная сумма
+	lz4DictId       byte   = 0x01       // В заголовке есть идентификатор словаря
+	lz4BlockMaxId   byte   = 6          // Максимальный размер блока 1М
+	lz4Uncompressed uint32 = 1 << 31    // Блок хранится без сжатия
+)
+
+// Параметры формата блока LZ4
+const (
+	lz4MinMatch     = 4  // Минимальная длина совпадения
+	lz4MfLimit      = 12 // Совпадение начинается не ближе к концу блока
+	lz4LastLiterals = 5  // Последние байты блока всегда литералы
+	lz4MaxOffset    = 65535
+	lz4HashLog      = 16
+)
+
+// Возвращает максимальный размер блока по его идентификатору
+func lz4BlockSize(id byte) int { return 1 << (2*int(id) + 8) }
+
+// Писатель кадра LZ4 с независимыми блоками.
+// Уровень сжатия не учитывается.
+type lz4Writer struct {
+	w      io.Writer
+	buf    []byte // Накопленные несжатые данные блока
+	out    []byte // Сжатый блок
+	header bool   // Заголовок кадра записан
+	table  [1 << lz4HashLog]int32
+}
+
+// Создает нового писателя кадра LZ4
+func newLZ4Writer(w io.Writer) *lz4Writer {
+	size := lz4BlockSize(lz4BlockMaxId)
+	return &lz4Writer{w: w, buf: make([]byte, 0, size)}
+}
+
+func (lw *lz4Writer) Write(p []byte) (n int, err error) {
+	if err = lw.writeHeader(); err != nil {
+		return 0, err
+	}
+
+	for len(p) > 0 {
+		copied := min(len(p), cap(lw.buf)-len(lw.buf))
+		lw.buf = append(lw.buf, p[:copied]...)
+		n += copied
+		p = p[copied:]
+
+		if len(lw.buf) == cap(lw.buf) {
+			if err = lw.flushBlock(); err != nil {
+				return n, err
+			}
+		}
+	}
+
+	return n, nil
+}
+
+// Дописывает последний блок и признак конца кадра
+func (lw *lz4Writer) Close() (err error) {
+	if err = lw.writeHeader(); err != nil {
+		return err
+	}
+
+	if len(lw.buf) > 0 {
+		if err = lw.flushBlock(); err != nil {
+			return err
+		}
+	}
+
+	return binary.Write(lw.w, binary.LittleEndian, uint32(0))
+}
+
+func (lw *lz4Writer) Reset(w io.Writer) {
+	lw.w, lw.buf, lw.header = w, lw.buf[:0], false
+}
+
+// Пишет заголовок кадра, если он еще не записан
+func (lw *lz4Writer) writeHeader() error {
+	if lw.header {
+		return nil
+	}
+	lw.header = true
+
+	descriptor := []byte{lz4Version | lz4BlockIndep, lz4BlockMaxId << 4}
+	header := binary.LittleEndian.AppendUint32(nil, lz4Magic)
+	header = append(header, descriptor...)
+	header = append(header, byte(xxh32(descriptor, 0)>>8))
+
+	_, err := lw.w.Write(header)
+	return err
+}
+
+// Сжимает и пишет накопленный блок. Если блок
+// не сжимается, то он пишется без сжатия.
+func (lw *lz4Writer) flushBlock() (err error) {
+	clear(lw.table[:])
+	lw.out = lz4CompressBlock(lw.buf, lw.out[:0], &lw.table)
+
+	size, data := uint32(len(lw.out)), lw.out
+	if len(lw.out) >= len(lw.buf) {
+		size, data = uint32(len(lw.buf))|lz4Uncompressed, lw.buf
+	}
+
+	if err = binary.Write(lw.w, binary.LittleEndian, size); err != nil {
+		return err
+	}
+	if _, err = lw.w.Write(data); err != nil {
+		return err
+	}
+
+	lw.buf = lw.buf[:0]
+	return nil
+}
+
+// Сжимает блок src жадным поиском совпадений по
+// хеш-таблице и дописывает результат в dst
+func lz4CompressBlock(src, dst []byte, table *[1 << lz4HashLog]int32) []byte {
+	var (
+		n      = len(src)
+		anchor = 0
+		limit  = n - lz4MfLimit
+		maxEnd = n - lz4LastLiterals
+	)
+
+	for i := 0; i < limit; {
+		seq := binary.LittleEndian.Uint32(src[i:])
+		h := (seq * 2654435761) >> (32 - lz4HashLog)
+		ref := int(table[h]) - 1
+		table[h] = int32(i + 1)
+
+		if ref < 0 || i-ref > lz4MaxOffset ||
+			binary.LittleEndian.Uint32(src[ref:]) != seq {
+			i += 1 + (i-anchor)>>6 // Ускоряемся на несжимаемых данных
+			continue
+		}
+
+		// Расширяем совпадение назад и вперед
+		for i > anchor && ref > 0 && src[i-1] == src[ref-1] {
+			i--
+			ref--
+		}
+		length := lz4MinMatch
+		for i+length < maxEnd && src[i+length] == src[ref+length] {
+			length++
+		}
+
+		dst = lz4AppendSequence(dst, src[anchor:i], i-ref, length)
+		i += length
+		anchor = i
+	}
+
+	return lz4AppendSequence(dst, src[anchor:], 0, 0)
+}
+
+// Дописывает в dst последовательность из литералов и
+// совпадения. Нулевое смещение -- последняя
+// последовательность блока без совпадения.
+func lz4AppendSequence(dst, literals []byte, offset, length int) []byte {
+	litLen, matchLen := len(literals), length-lz4MinMatch
+	if offset == 0 {
+		matchLen = 0
+	}
+
+	dst = append(dst, byte(min(litLen, 15))<<4|byte(min(matchLen, 15)))
+	if litLen >= 15 {
+		dst = lz4AppendLength(dst, litLen-15)
+	}
+	dst = append(dst, literals...)
+
+	if offset == 0 {
+		return dst
+	}
+
+	dst = binary.LittleEndian.AppendUint16(dst, uint16(offset))
+	if matchLen >= 15 {
+		dst = lz4AppendLength(dst, matchLen-15)
+	}
+
+	return dst
+}
+
+// Дописывает продолжение длины байтами по 255
+func lz4AppendLength(dst []byte, length int) []byte {
+	for ; length >= 255; length -= 255 {
+		dst = append(dst, 255)
+	}
+
+	return append(dst, byte(length))
+}
+
+// Читатель кадра LZ4
+type lz4Reader struct {
+	r          io.Reader
+	buf        []byte // Распакованный блок
+	pos        int    // Позиция в распакованном блоке
+	comp       []byte // Сжатый блок
+	header     bool   // Заголовок кадра прочитан
+	eof        bool   // Прочитан признак конца кадра
+	blockSize  int    // Максимальный размер блока
+	blockCRC   bool
+	contentCRC bool
+	indep      bool // Блоки не ссылаются на предыдущие
+}
+
+// Создает нового читателя кадра LZ4. Заголовок
+// кадра читается при первом чтении.
+func newLZ4Reader(r io.Reader) *lz4Reader {
+	return &lz4Reader{r: r}
+}
+
+func (lr *lz4Reader) Read(p []byte) (n int, err error) {
+	for lr.pos == len(lr.buf) {
+		if lr.eof {
+			return 0, io.EOF
+		}
+
+		if !lr.header {
+			if err = lr.readHeader(); err != nil {
+				return 0, err
+			}
+		}
+
+		if err = lr.readBlock(); err != nil {
+			return 0, err
+		}
+	}
+
+	n = copy(p, lr.buf[lr.pos:])
+	lr.pos += n
+
+	return n, nil
+}
+
+func (lr *lz4Reader) Close() error { return nil }
+
+func (lr *lz4Reader) Reset(r io.Reader) error {
+	lr.r, lr.buf, lr.pos = r, lr.buf[:0], 0
+	lr.header, lr.eof = false, false
+	return nil
+}
+
+// Читает и проверяет заголовок кадра
+func (lr *lz4Reader) readHeader() (err error) {
+	var (
+		magic      uint32
+		descriptor = make([]byte, 2, 14)
+	)
+
+	if err = binary.Read(lr.r, binary.LittleEndian, &magic); err != nil {
+		return err
+	}
+	if magic != lz4Magic {
+		return ErrLZ4Header
+	}
+
+	if _, err = io.ReadFull(lr.r, descriptor); err != nil {
+		return err
+	}
+
+	flg, bd := descriptor[0], descriptor[1]
+	if flg&0xc0 != lz4Version || flg&lz4DictId != 0 {
+		return ErrLZ4Header
+	}
+
+	if id := bd >> 4 & 7; id >= 4 {
+		lr.blockSize = lz4BlockSize(id)
+	} else {
+		return ErrLZ4Header
+	}
+
+	if flg&lz4ContentSize != 0 { // Размер содержимого не нужен
+		descriptor = descriptor[:10]
+		if _, err = io.ReadFull(lr.r, descriptor[2:]); err != nil {
+			return err
+		}
+	}
+
+	var checksum [1]byte
+	if _, err = io.ReadFull(lr.r, checksum[:]); err != nil {
+		return err
+	}
+	if checksum[0] != byte(xxh32(descriptor, 0)>>8) {
+		return ErrLZ4Header
+	}
+
+	lr.indep = flg&lz4BlockIndep != 0
+	lr.blockCRC = flg&lz4BlockCRC != 0
+	lr.contentCRC = flg&lz4ContentCRC != 0
+	lr.header = true
+
+	return nil
+}
+
+// Читает и распаковывает следующий блок. Контрольные
+// суммы блоков и содержимого пропускаются, целостность
+// данных проверяется по CRC архива.
+func (lr *lz4Reader) readBlock() (err error) {
+	var size uint32
+
+	if err = binary.Read(lr.r, binary.LittleEndian, &size); err != nil {
+		return noEOF(err)
+	}
+
+	// Зависимые блоки ссылаются на последние 64К предыдущего
+	history := 0
+	if !lr.indep {
+		history = min(len(lr.buf), lz4MaxOffset+1)
+		lr.buf = append(lr.buf[:0], lr.buf[len(lr.buf)-history:]...)
+	} else {
+		lr.buf = lr.buf[:0]
+	}
+	lr.pos = history
+
+	if size == 0 { // Признак конца кадра
+		lr.eof = true
+		if lr.contentCRC {
+			_, err = io.CopyN(io.Discard, lr.r, 4)
+		}
+		return noEOF(err)
+	}
+
+	uncompressed := size&lz4Uncompressed != 0
+	size &^= lz4Uncompressed
+	if int(size) > lr.blockSize {
+		return ErrLZ4Block
+	}
+
+	if cap(lr.comp) < int(size) {
+		lr.comp = make([]byte, size)
+	}
+	lr.comp = lr.comp[:size]
+	if _, err = io.ReadFull(lr.r, lr.comp); err != nil {
+		return noEOF(err)
+	}
+
+	if lr.blockCRC {
+		if _, err = io.CopyN(io.Discard, lr.r, 4); err != nil {
+			return noEOF(err)
+		}
+	}
+
+	if uncompressed {
+		lr.buf = append(lr.buf, lr.comp...)
+		return nil
+	}
+
+	lr.buf, err = lz4DecompressBlock(lr.comp, lr.buf, history+lr.blockSize)
+	return err
+}
+
+// Распаковывает блок src в dst, размер
+// распакованных данных не больше maxSize
+func lz4DecompressBlock(src, dst []byte, maxSize int) ([]byte, error) {
+	var err error
+
+	for i := 0; i < len(src); {
+		token := src[i]
+		i++
+
+		litLen := int(token >> 4)
+		if litLen == 15 {
+			if litLen, i, err = lz4ReadLength(src, i, litLen); err != nil {
+				return nil, err
+			}
+		}
+		if litLen > len(src)-i || len(dst)+litLen > maxSize {
+			return nil, ErrLZ4Block
+		}
+		dst = append(dst, src[i:i+litLen]...)
+		i += litLen
+
+		if i == len(src) { // Последняя последовательность
+			break
+		}
+
+		if len(src)-i < 2 {
+			return nil, ErrLZ4Block
+		}
+		offset := int(binary.LittleEndian.Uint16(src[i:]))
+		i += 2
+
+		length := int(token & 15)
+		if length == 15 {
+			if length, i, err = lz4ReadLength(src, i, length); err != nil {
+				return nil, err
+			}
+		}
+		length += lz4MinMatch
+
+		if offset == 0 || offset > len(dst) || len(dst)+length > maxSize {
+			return nil, ErrLZ4Block
+		}
+
+		pos := len(dst) - offset
+		if offset >= length {
+			dst = append(dst, dst[pos:pos+length]...)
+		} else { // Совпадение перекрывает само себя
+			for k := range length {
+				dst = append(dst, dst[pos+k])
+			}
+		}
+	}
+
+	return dst, nil
+}
+
+// Читает продолжение длины из src с позиции i
+func lz4ReadLength(src []byte, i, length int) (int, int, error) {
+	for {
+		if i >= len(src) {
+			return 0, 0, ErrLZ4Block
+		}
+
+		b := src[i]
+		i++
+		length += int(b)
+
+		if b != 255 {
+			return length, i, nil
+		}
+	}
+}
+
+// Заменяет конец потока внутри кадра на [io.ErrUnexpectedEOF]
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+
+	return err
+}
+
+// Простые числа xxHash32
+const (
+	xxhPrime1 uint32 = 2654435761
+	xxhPrime2 uint32 = 2246822519
+	xxhPrime3 uint32 = 3266489917
+	xxhPrime4 uint32 = 668265263
+	xxhPrime5 uint32 = 374761393
+)
+
+// Вычисляет xxHash32 от b, нужен для
+// контрольной суммы заголовка кадра
+func xxh32(b []byte, seed uint32) uint32 {
+	var (
+		n = uint32(len(b))
+		h uint32
+	)
+
+	round := func(acc, in uint32) uint32 {
+		return bits.RotateLeft32(acc+in*xxhPrime2, 13) * xxhPrime1
+	}
+
+	if len(b) >= 16 {
+		v1, v2 := seed+xxhPrime1+xxhPrime2, seed+xxhPrime2
+		v3, v4 := seed, seed-xxhPrime1
+
+		for ; len(b) >= 16; b = b[16:] {
+			v1 = round(v1, binary.LittleEndian.Uint32(b[0:]))
+			v2 = round(v2, binary.LittleEndian.Uint32(b[4:]))
+			v3 = round(v3, binary.LittleEndian.Uint32(b[8:]))
+			v4 = round(v4, binary.LittleEndian.Uint32(b[12:]))
+		}
+
+		h = bits.RotateLeft32(v1, 1) + bits.RotateLeft32(v2, 7) +
+			bits.RotateLeft32(v3, 12) + bits.RotateLeft32(v4, 18)
+	} else {
+		h = seed + xxhPrime5
+	}
+	h += n
+
+	for ; len(b) >= 4; b = b[4:] {
+		h += binary.LittleEndian.Uint32(b) * xxhPrime3
+		h = bits.RotateLeft32(h, 17) * xxhPrime4
+	}
+
+	for _, c := range b {
+		h += uint32(c) * xxhPrime5
+		h = bits.RotateLeft32(h, 11) * xxhPrime1
+	}
+
+	h ^= h >> 15
+	h *= xxhPrime2
+	h ^= h >> 13
+	h *= xxhPrime3
+	h ^= h >> 16
+
+	return h
+}
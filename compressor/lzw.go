@@ -0,0 +1,95 @@
+package compressor
+
+import (
+	"compress/lzw"
+	"io"
+	"sync"
+)
+
+// Параметры кодирования LZW
+type LzwParams struct {
+	Order    lzw.Order // Порядок бит в кодах
+	LitWidth byte      // Разрядность литералов, от 2 до 8 бит
+}
+
+// Параметры LZW по умолчанию, с ними
+// сжаты архивы старых версий
+var DefaultLzwParams = LzwParams{lzw.MSB, 8}
+
+var (
+	lzwMu     sync.RWMutex
+	lzwParams = DefaultLzwParams
+)
+
+// Проверяет допустимость параметров
+func (lp LzwParams) Valid() bool {
+	return (lp.Order == lzw.LSB || lp.Order == lzw.MSB) &&
+		lp.LitWidth >= 2 && lp.LitWidth <= 8
+}
+
+// Реализация fmt.Stringer
+func (lp LzwParams) String() string {
+	order := "MSB"
+	if lp.Order == lzw.LSB {
+		order = "LSB"
+	}
+
+	return order + ", литералы " + string('0'+lp.LitWidth) + " бит"
+}
+
+// Устанавливает параметры для новых читателей и
+// писателей LZW. Созданные ранее их не меняют.
+func SetLzwParams(lp LzwParams) error {
+	if !lp.Valid() {
+		return ErrLzwParams(byte(lp.Order), lp.LitWidth)
+	}
+
+	lzwMu.Lock()
+	lzwParams = lp
+	lzwMu.Unlock()
+
+	return nil
+}
+
+// Возвращает текущие параметры LZW
+func CurrentLzwParams() LzwParams {
+	lzwMu.RLock()
+	defer lzwMu.RUnlock()
+
+	return lzwParams
+}
+
+// Адаптер для [lzw.Reader]
+type lzwReader struct {
+	*lzw.Reader
+	params LzwParams
+}
+
+func newLzwReader(r io.Reader) *lzwReader {
+	lp := CurrentLzwParams()
+	return &lzwReader{
+		lzw.NewReader(r, lp.Order, int(lp.LitWidth)).(*lzw.Reader), lp,
+	}
+}
+
+func (lr *lzwReader) Reset(r io.Reader) error {
+	lr.Reader.Reset(r, lr.params.Order, int(lr.params.LitWidth))
+	return nil
+}
+
+// Адаптер для [lzw.Writer]
+type lzwWriter struct {
+	*lzw.Writer
+	params LzwParams
+}
+
+func newLzwWriter(w io.Writer) *lzwWriter {
+	lp := CurrentLzwParams()
+	return &lzwWriter{
+		lzw.NewWriter(w, lp.Order, int(lp.LitWidth)).(*lzw.Writer), lp,
+	}
+}
+
+func (lw *lzwWriter) Reset(w io.Writer) {
+	lw.Writer.Reset(w, lw.params.Order, int(lw.params.LitWidth))
+}
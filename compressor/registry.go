@@ -0,0 +1,155 @@
+package compressor
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Конструктор читателя компрессора, dict -- предустановленный
+// словарь архива или nil
+type NewReaderFunc func(r io.Reader, dict []byte) (ReadCloseResetter, error)
+
+// Конструктор писателя компрессора, dict -- предустановленный
+// словарь архива или nil
+type NewWriterFunc func(w io.Writer, l Level, dict []byte) (WriteCloseResetter, error)
+
+// Максимальный полезный размер словаря, равен окну DEFLATE
+const MaxDictSize = 32 << 10
+
+// Диапазон уровней сжатия, которые принимает компрессор.
+// Компрессоры без уровней сжатия принимают только
+// [DefaultCompression].
+type LevelRange struct {
+	Min, Max Level
+}
+
+// Проверяет, входит ли уровень l в диапазон
+func (lr LevelRange) Contains(l Level) bool {
+	return l >= lr.Min && l <= lr.Max
+}
+
+// Проверяет, учитывает ли компрессор уровень сжатия
+func (lr LevelRange) Adjustable() bool {
+	return lr.Min != lr.Max
+}
+
+// Описание зарегистрированного компрессора
+type codec struct {
+	name      string
+	newReader NewReaderFunc
+	newWriter NewWriterFunc
+	levels    LevelRange
+	dict      bool // Использует предустановленный словарь
+}
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[Type]codec{}
+)
+
+// Регистрирует компрессор с идентификатором id и именем name.
+// Идентификатор записывается в архив, поэтому не должен
+// меняться между версиями. Встроенные компрессоры занимают
+// младшие идентификаторы, для сторонних стоит брать от 128.
+// Если newWriter равен nil, то компрессор поддерживает только
+// распаковку. Повторная регистрация id или имени -- паника.
+func Register(id Type, name string, newReader NewReaderFunc, newWriter NewWriterFunc, levels LevelRange) {
+	register(id, name, newReader, newWriter, levels, false)
+}
+
+// Регистрирует компрессор, dict -- признак
+// использования предустановленного словаря
+func register(id Type, name string, newReader NewReaderFunc, newWriter NewWriterFunc, levels LevelRange, dict bool) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+
+	if newReader == nil {
+		panic("compressor: конструктор читателя для " + name + " равен nil")
+	}
+
+	if c, dup := codecs[id]; dup {
+		panic(fmt.Sprintf("compressor: идентификатор %d уже занят %s", id, c.name))
+	}
+
+	for _, c := range codecs {
+		if strings.EqualFold(c.name, name) {
+			panic("compressor: имя " + name + " уже зарегистрировано")
+		}
+	}
+
+	codecs[id] = codec{name, newReader, newWriter, levels, dict}
+}
+
+// Удаляет компрессор типа ct из реестра
+func unregister(ct Type) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+
+	delete(codecs, ct)
+}
+
+// Возвращает описание компрессора типа ct
+func lookup(ct Type) (codec, bool) {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+
+	c, ok := codecs[ct]
+	return c, ok
+}
+
+// Ищет тип компрессора по имени без учета регистра
+func Lookup(name string) (Type, bool) {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+
+	for ct, c := range codecs {
+		if strings.EqualFold(c.name, name) {
+			return ct, true
+		}
+	}
+
+	return 0, false
+}
+
+// Проверяет, зарегистрирован ли компрессор типа ct
+func Registered(ct Type) bool {
+	_, ok := lookup(ct)
+	return ok
+}
+
+// Проверяет, может ли компрессор типа ct сжимать
+func CanCompress(ct Type) bool {
+	c, ok := lookup(ct)
+	return ok && c.newWriter != nil
+}
+
+// Проверяет, использует ли компрессор типа ct
+// предустановленный словарь
+func SupportsDict(ct Type) bool {
+	c, ok := lookup(ct)
+	return ok && c.dict
+}
+
+// Возвращает диапазон уровней сжатия компрессора типа ct
+func Levels(ct Type) (LevelRange, bool) {
+	c, ok := lookup(ct)
+	return c.levels, ok
+}
+
+// Возвращает упорядоченные по идентификатору типы
+// зарегистрированных компрессоров
+func Types() []Type {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+
+	types := make([]Type, 0, len(codecs))
+	for ct := range codecs {
+		types = append(types, ct)
+	}
+	slices.Sort(types)
+
+	return types
+}
@@ -2,3 +2,4 @@ module archiver
 
 go 1.23.4
 
+require golang.org/x/sys v0.35.0
@@ -23,7 +23,7 @@ func main() {
 	go func() {
 		<-sigChan
 		fmt.Println("Прерываю...")
-		if len(p.InputPaths) > 0 {
+		if len(p.InputPaths) > 0 || p.Remove || p.Rename || len(p.Sources) > 0 {
 			a.RemoveTmp()
 		}
 		os.Exit(0)
@@ -34,6 +34,15 @@ func main() {
 		p.PrintNopLevelIgnore()
 		params.PrintPathsIgnore()
 		err = a.Compress(p.InputPaths)
+	case p.Merge:
+		err = a.Merge(p.Sources, p.Duplicates)
+	case p.Transcode:
+		p.PrintNopLevelIgnore()
+		err = a.Transcode(p.Sources[0])
+	case p.Remove:
+		err = a.Remove(p.Patterns)
+	case p.Rename:
+		err = a.Rename(p.Patterns)
 	case p.PrintStat:
 		params.PrintStatIgnore()
 		err = a.ViewStat()
@@ -45,7 +54,7 @@ func main() {
 		err = a.IntegrityTest()
 	default:
 		params.PrintDecompressIgnore()
-		err = a.Decompress()
+		err = a.Decompress(p.Patterns)
 	}
 
 	if err != nil {
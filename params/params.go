@@ -2,6 +2,8 @@ package params
 
 import (
 	"archiver/compressor"
+	"archiver/filesystem"
+	"compress/lzw"
 	"flag"
 	"fmt"
 	"io"
@@ -26,8 +28,50 @@ type Params struct {
 	MemStat bool
 	// Флаг замены всех файлов при распаковке без подтверждения
 	ReplaceAll bool
+	// Флаг сохранения несжимаемых файлов без сжатия
+	AutoStore bool
+	// Флаг восстановления владельца и группы при распаковке
+	RestoreOwner bool
+	// Флаг восстановления расширенных атрибутов при распаковке
+	RestoreXattrs bool
+	// Путь к файлу словаря или 'auto'
+	DictPath string
+	// Параметры кодирования LZW
+	Lzw compressor.LzwParams
+	// Флаг сжатия мелких файлов общим потоком
+	Solid bool
+	// Флаг дедупликации фрагментов файлов
+	Dedup bool
+	// Флаг дополнения существующего архива
+	Update bool
+	// Флаг удаления элементов архива по шаблонам
+	Remove bool
+	// Флаг переименования элементов архива по префиксу
+	Rename bool
+	// Флаг распаковки только элементов, совпадающих с шаблонами
+	Extract bool
+	// Шаблоны путей или пары префиксов после имени архива
+	Patterns []string
+	// Флаг объединения архивов
+	Merge bool
+	// Правило выбора элемента из одинаковых путей при объединении
+	Duplicates DupPolicy
+	// Флаг перекодирования архива другим компрессором
+	Transcode bool
+	// Пути к объединяемому или перекодируемому архивам
+	Sources []string
 }
 
+// Правило выбора элемента из одинаковых путей
+// в разных архивах при объединении
+type DupPolicy byte
+
+const (
+	DupError DupPolicy = iota // Одинаковые пути -- ошибка
+	DupFirst                  // Остается элемент из первого архива
+	DupLast                   // Остается элемент из последнего архива
+)
+
 // Печатает справку
 func PrintHelp() {
 	program := filepath.Base(os.Args[0])
@@ -35,6 +79,10 @@ func PrintHelp() {
 	fmt.Println("Сжатие:    ", program, compExample)
 	fmt.Println("Распаковка:", program, decompExample)
 	fmt.Println("Просмотр:  ", program, viewExample)
+	fmt.Println("Удаление:  ", program, removeExample)
+	fmt.Println("Перенос:   ", program, renameExample)
+	fmt.Println("Слияние:   ", program, mergeExample)
+	fmt.Println("Пересжатие:", program, transcodeExample)
 	fmt.Printf("\nФлаги:\n")
 
 	flag.PrintDefaults()
@@ -47,10 +95,16 @@ func ParseParams() (p Params) {
 	flag.StringVar(&p.OutputDir, "o", "", outputDirDesc)
 
 	var level int
-	flag.IntVar(&level, "L", -1, levelDesc)
+	flag.IntVar(&level, "L", -1, fmt.Sprintf(levelDesc, fixedLevelNames()))
 
 	var compType string
-	flag.StringVar(&compType, "c", "gzip", compDesc)
+	flag.StringVar(&compType, "c", "gzip", fmt.Sprintf(compDesc, compNames()))
+	flag.StringVar(&p.DictPath, "dict", "", fmt.Sprintf(dictDesc, dictNames()))
+
+	var lzwOrder string
+	var lzwLitWidth int
+	flag.StringVar(&lzwOrder, "lzworder", "msb", lzwOrderDesc)
+	flag.IntVar(&lzwLitWidth, "lzwlit", 8, lzwLitDesc)
 
 	flag.BoolVar(&p.PrintStat, "s", false, statDesc)
 	flag.BoolVar(&p.PrintList, "l", false, listDesc)
@@ -58,6 +112,17 @@ func ParseParams() (p Params) {
 	flag.BoolVar(&p.XIntegTest, "xinteg", false, xIntegDesc)
 	flag.BoolVar(&p.MemStat, "mstat", false, memStatDesc)
 	flag.BoolVar(&p.ReplaceAll, "f", false, relaceAllDesc)
+	flag.BoolVar(&p.RestoreOwner, "chown", false, chownDesc)
+	flag.BoolVar(&p.RestoreXattrs, "xattr", false, xattrDesc)
+	flag.BoolVar(&p.Solid, "solid", false, solidDesc)
+	flag.BoolVar(&p.Dedup, "dedup", false, dedupDesc)
+	flag.BoolVar(&p.Update, "u", false, updateDesc)
+	flag.BoolVar(&p.Remove, "rm", false, removeDesc)
+	flag.BoolVar(&p.Rename, "mv", false, renameDesc)
+	flag.BoolVar(&p.Extract, "x", false, extractDesc)
+	flag.BoolVar(&p.Merge, "merge", false, mergeDesc)
+	dup := flag.String("dup", "error", dupDesc)
+	flag.BoolVar(&p.Transcode, "transcode", false, transcodeDesc)
 
 	logging := flag.Bool("log", false, logDesc)
 	version := flag.Bool("V", false, versionDesc)
@@ -82,9 +147,17 @@ func ParseParams() (p Params) {
 	}
 
 	p.checkPaths()
+	p.checkEdit()
+	p.checkMerge(*dup)
+	p.checkTranscode(compType, level)
+	p.checkExtract()
 	if len(p.InputPaths) > 0 {
 		p.checkCompType(compType)
+		p.checkDict()
+		p.checkLzw(lzwOrder, lzwLitWidth)
 		p.checkCompLevel(level)
+		p.checkDedup()
+		p.checkUpdate()
 	}
 
 	return p
@@ -105,34 +178,35 @@ func (p Params) PrintNopLevelIgnore() {
 // Флаги которые могут быть проигнорированы
 // другими флагами
 var ignores = []string{
-	"f", "o", "xinteg", "integ", "l", "s", "c", "L",
+	"xattr", "chown", "f", "o", "xinteg", "integ", "l", "s", "c", "L",
+	"dict", "lzworder", "lzwlit", "solid", "dedup", "u", "rm", "mv",
 }
 
 // Явный вывод какие флаги игнорирует
 // наличие путей после имени архива
 func PrintPathsIgnore() {
-	printIgnore("Наличие путей после имени архива", ignores[:6])
+	printIgnore("Наличие путей после имени архива", ignores[:8])
 }
 
 // Явный вывод какие флаги игнорирует флаг '-s'
 func PrintStatIgnore() {
-	printIgnore("Наличие флага 's'", append(ignores[:5], ignores[6:]...))
+	printIgnore("Наличие флага 's'", append(ignores[:7], ignores[8:]...))
 }
 
 // Явный вывод какие флаги игнорирует флаг '-l'
 func PrintListIgnore() {
-	printIgnore("Наличие флага 'l'", append(ignores[:4], ignores[5:]...))
+	printIgnore("Наличие флага 'l'", append(ignores[:6], ignores[7:]...))
 }
 
 // Явный вывод какие флаги игнорирует флаг '--integ'
 func PrintIntegIgnore() {
-	printIgnore("Наличие флага 'integ'", append(ignores[:3], ignores[4:]...))
+	printIgnore("Наличие флага 'integ'", append(ignores[:5], ignores[6:]...))
 }
 
 // Явный вывод какие флаги игнорирует флаг
 // отсутствие путей после имени архива
 func PrintDecompressIgnore() {
-	printIgnore("Отсутствие путей после имени архива", ignores[3:])
+	printIgnore("Отсутствие путей после имени архива", ignores[5:])
 }
 
 // Общий шаблон вывода информации о том какие
@@ -151,32 +225,240 @@ func printIgnore(fstr string, ignores []string) {
 	})
 }
 
-// Проверяет параметр уровня сжатия
+// Проверяет параметр уровня сжатия по диапазону
+// уровней выбранного компрессора. Уровень 0
+// выбирает сохранение без сжатия для любого
+// компрессора.
 func (p *Params) checkCompLevel(level int) {
 	p.Cl = compressor.Level(level)
-	if p.Cl < -2 || p.Cl > 9 {
-		printError(compLevelError)
-	} else if p.Cl == 0 {
+	if p.Cl == compressor.NoCompression {
 		p.Ct = compressor.Nop
+		return
+	}
+
+	if !isFlagSet("L") {
+		return
+	}
+
+	levels, _ := compressor.Levels(p.Ct)
+	if !levels.Adjustable() {
+		printError(fmt.Sprintf(compLevelIgnoreError, p.Ct))
+	} else if !levels.Contains(p.Cl) {
+		printError(fmt.Sprintf(compLevelError, p.Ct, levels.Min, levels.Max))
+	}
+}
+
+// Проверяет совместимость дедупликации с solid-группами
+func (p Params) checkDedup() {
+	if p.Dedup && p.Solid {
+		printError(dedupSolidError)
+	}
+}
+
+// Проверяет, что при дополнении архива не заданы
+// параметры, которые берутся из самого архива
+func (p Params) checkUpdate() {
+	if p.Update && (isFlagSet("dict") || isFlagSet("lzworder") || isFlagSet("lzwlit")) {
+		printError(updateParamsError)
+	}
+}
+
+// Проверяет флаги изменения архива. Пути после имени
+// архива становятся шаблонами удаления или парами
+// префиксов для переименования.
+func (p *Params) checkEdit() {
+	if !p.Remove && !p.Rename {
+		return
+	} else if p.Remove && p.Rename {
+		printError(editFlagsError)
+	}
+
+	p.Patterns, p.InputPaths = p.InputPaths, nil
+	if len(p.Patterns) == 0 {
+		printError(editPatternsError)
+	} else if p.Rename && len(p.Patterns)%2 != 0 {
+		printError(renamePairsError)
+	}
+
+	for i, pattern := range p.Patterns {
+		if p.Patterns[i] = filesystem.Clean(pattern); p.Patterns[i] == "" {
+			printError(editPatternsError)
+		}
+	}
+}
+
+// Проверяет флаг выборочной распаковки. Пути после
+// имени архива становятся шаблонами путей в архиве.
+func (p *Params) checkExtract() {
+	if !p.Extract {
+		return
+	} else if p.Remove || p.Rename || p.Merge || p.Transcode || p.Update ||
+		p.PrintList || p.PrintStat || p.IntegTest {
+		printError(extractFlagsError)
+	}
+
+	p.Patterns, p.InputPaths = p.InputPaths, nil
+	if len(p.Patterns) == 0 {
+		printError(editPatternsError)
+	}
+
+	for i, pattern := range p.Patterns {
+		if p.Patterns[i] = filesystem.Clean(pattern); p.Patterns[i] == "" {
+			printError(editPatternsError)
+		}
+	}
+}
+
+// Проверяет флаги объединения архивов. Пути после
+// имени архива становятся объединяемыми архивами.
+func (p *Params) checkMerge(dup string) {
+	if !p.Merge {
+		if isFlagSet("dup") {
+			printError(dupMergeError)
+		}
+		return
+	} else if p.Remove || p.Rename || p.Update {
+		printError(mergeFlagsError)
+	}
+
+	p.Sources, p.InputPaths = p.InputPaths, nil
+	if len(p.Sources) == 0 {
+		printError(mergeSourcesError)
+	}
+
+	switch strings.ToLower(dup) {
+	case "error":
+		p.Duplicates = DupError
+	case "first":
+		p.Duplicates = DupFirst
+	case "last":
+		p.Duplicates = DupLast
+	default:
+		printError(dupError)
+	}
+}
+
+// Проверяет флаги перекодирования архива. Путь после
+// имени нового архива указывает на исходный архив.
+func (p *Params) checkTranscode(compType string, level int) {
+	if !p.Transcode {
+		return
+	} else if p.Merge || p.Remove || p.Rename || p.Update {
+		printError(transcodeFlagsError)
+	} else if isFlagSet("dict") || isFlagSet("lzworder") || isFlagSet("lzwlit") {
+		printError(transcodeParamsError)
+	}
+
+	p.Sources, p.InputPaths = p.InputPaths, nil
+	if len(p.Sources) != 1 {
+		printError(transcodeSourceError)
+	}
+
+	p.checkCompType(compType)
+	p.checkCompLevel(level)
+}
+
+// Проверяет параметры кодирования LZW
+func (p *Params) checkLzw(order string, litWidth int) {
+	if (isFlagSet("lzworder") || isFlagSet("lzwlit")) &&
+		p.Ct != compressor.LempelZivWelch {
+		printError(lzwError)
+	}
+
+	p.Lzw = compressor.DefaultLzwParams
+	switch strings.ToLower(order) {
+	case "msb":
+		p.Lzw.Order = lzw.MSB
+	case "lsb":
+		p.Lzw.Order = lzw.LSB
+	default:
+		printError(lzwOrderError)
+	}
+
+	if litWidth < 2 || litWidth > 8 {
+		printError(lzwLitError)
+	}
+	p.Lzw.LitWidth = byte(litWidth)
+}
+
+// Проверяет, указан ли флаг name явно
+func isFlagSet(name string) (set bool) {
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+
+	return set
+}
+
+// Возвращает через запятую имена компрессоров,
+// которые не учитывают уровень сжатия
+func fixedLevelNames() string {
+	var names []string
+	for _, ct := range compressor.Types() {
+		levels, _ := compressor.Levels(ct)
+		if ct != compressor.Nop && compressor.CanCompress(ct) && !levels.Adjustable() {
+			names = append(names, ct.String())
+		}
 	}
+
+	return strings.Join(names, ", ")
 }
 
 // Проверяет параметр типа компрессора
 func (p *Params) checkCompType(compType string) {
 	compType = strings.ToLower(compType)
 
-	switch compType {
-	case "gzip":
+	if compType == "auto" {
 		p.Ct = compressor.GZip
-	case "lzw":
-		p.Ct = compressor.LempelZivWelch
-	case "zlib":
-		p.Ct = compressor.ZLib
-	default:
+		p.AutoStore = true
+		return
+	}
+
+	// Без сжатия выбирается уровнем 0
+	ct, ok := compressor.Lookup(compType)
+	if !ok || ct == compressor.Nop {
 		printError(compTypeError)
+	} else if !compressor.CanCompress(ct) {
+		printError(fmt.Sprintf(compReadOnlyError, ct))
+	}
+	p.Ct = ct
+}
+
+// Проверяет, что выбранный компрессор использует словарь
+func (p *Params) checkDict() {
+	if p.DictPath != "" && !compressor.SupportsDict(p.Ct) {
+		printError(fmt.Sprintf(dictError, dictNames()))
 	}
 }
 
+// Возвращает через запятую имена компрессоров,
+// использующих предустановленный словарь
+func dictNames() string {
+	var names []string
+	for _, ct := range compressor.Types() {
+		if compressor.SupportsDict(ct) {
+			names = append(names, ct.String())
+		}
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// Возвращает через запятую имена компрессоров,
+// которые можно выбрать флагом '-c'
+func compNames() string {
+	var names []string
+	for _, ct := range compressor.Types() {
+		if ct != compressor.Nop && compressor.CanCompress(ct) {
+			names = append(names, ct.String())
+		}
+	}
+
+	return strings.Join(names, ", ")
+}
+
 // Проверяет пути к файлам и архиву
 func (p *Params) checkPaths() {
 	if len(flag.Args()) == 0 {
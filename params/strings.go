@@ -21,17 +21,24 @@ USE OR OTHER DEALINGS IN THE SOFTWARE.
 Автор: Alexey Sorokin.
 `
 
-	compExample   = "[Флаги] <путь до архива> <список директории, файлов для сжатия>"
-	decompExample = "[-o <путь к директории для распаковки>] <путь до архива>"
-	viewExample   = "[-l | -s] <путь до архива>"
+	compExample      = "[Флаги] <путь до архива> <список директории, файлов для сжатия>"
+	decompExample    = "[-o <путь к директории для распаковки>] [-x] <путь до архива> [<шаблоны путей в архиве>]"
+	viewExample      = "[-l | -s] <путь до архива>"
+	removeExample    = "-rm <путь до архива> <шаблоны путей в архиве>"
+	renameExample    = "-mv <путь до архива> <старый префикс> <новый префикс> ..."
+	mergeExample     = "-merge [-dup first | last | error] <путь до нового архива> <объединяемые архивы>"
+	transcodeExample = "-transcode [-c <компрессор>] [-L <уровень>] <путь до нового архива> <исходный архив>"
 
 	outputDirDesc = "Путь к директории для распаковки"
-	levelDesc     = `Уровень сжатия от -2 до 9 (Не применяется для LZW)
+	levelDesc     = `Уровень сжатия от -2 до 9 (Не применяется для %s)
  -2 -- HuffmanOnly
  -1 -- DefaultCompression
   0 -- Без сжатия
 1-9 -- Произвольная степень сжатия`
-	compDesc      = "Тип компрессора: GZip, LZW, ZLib"
+	compDesc = `Тип компрессора: %s, Auto
+ Auto -- GZip, несжимаемые файлы сохраняются без сжатия`
+	dictDesc = `Файл предустановленного словаря для %s
+ auto -- словарь строится из выборки входных файлов`
 	helpDesc      = "Показать эту помощь"
 	statDesc      = "Печать информации о сжатии и выход (игнорирует -l)"
 	listDesc      = "Печать списка файлов и выход"
@@ -39,12 +46,44 @@ USE OR OTHER DEALINGS IN THE SOFTWARE.
 	xIntegDesc    = "Распаковка с учетом проверки целостности данных в архиве"
 	memStatDesc   = "Печать статистики использования ОЗУ после выполнения"
 	relaceAllDesc = "Автоматически заменять файлы при распаковке без подтверждения"
+	chownDesc     = "Восстанавливать владельца и группу при распаковке"
+	xattrDesc     = "Восстанавливать расширенные атрибуты при распаковке"
 	logDesc       = "Печатать логи"
+	solidDesc     = "Сжимать мелкие файлы общим потоком (solid-группами)"
+	dedupDesc     = "Хранить повторяющиеся фрагменты файлов один раз (дедупликация)"
+	updateDesc    = "Дописать новые и измененные элементы в конец существующего архива"
+	extractDesc   = "Распаковать только элементы, совпадающие с шаблонами после имени архива, вместе с содержимым директорий"
+	removeDesc    = "Удалить из архива элементы, совпадающие с шаблонами, вместе с содержимым директорий"
+	renameDesc    = "Переименовать элементы архива, заменив префиксы путей по парам"
+	mergeDesc     = "Объединить архивы в новый архив без повторного сжатия"
+	dupDesc       = "Элемент из одинаковых путей при объединении: first -- из первого архива, last -- из последнего, error -- ошибка"
+	transcodeDesc = "Сжать данные архива заново другим компрессором или уровнем, сохранив заголовки элементов"
+	lzwOrderDesc  = "Порядок бит в кодах LZW: msb или lsb"
+	lzwLitDesc    = "Разрядность литералов LZW от 2 до 8 бит, все байты входных файлов должны в нее помещаться"
 
 	zeroLevel = "Флаг '-L' со значением '0' игнорирует '-c'"
 
-	compLevelError            = "Уровень сжатия должен быть в пределах от -2 до 9"
+	compLevelError            = "Уровень сжатия %s должен быть в пределах от %d до %d"
+	compLevelIgnoreError      = "%s не поддерживает уровни сжатия, кроме 0"
+	lzwError                  = "Флаги '-lzworder' и '-lzwlit' применяются только для LZW"
+	lzwOrderError             = "Порядок бит LZW должен быть msb или lsb"
+	lzwLitError               = "Разрядность литералов LZW должна быть в пределах от 2 до 8"
 	compTypeError             = "Неизвестный тип компрессора"
+	compReadOnlyError         = "%s поддерживается только для распаковки"
+	dictError                 = "Словарь поддерживается только для %s"
+	dedupSolidError           = "Флаги '-dedup' и '-solid' несовместимы"
+	updateParamsError         = "Флаги '-dict', '-lzworder' и '-lzwlit' не применяются с '-u', используются параметры архива"
+	editFlagsError            = "Флаги '-rm' и '-mv' несовместимы"
+	editPatternsError         = "Не указаны пути элементов в архиве"
+	extractFlagsError         = "Флаг '-x' несовместим с '-rm', '-mv', '-merge', '-transcode', '-u', '-l', '-s' и '-integ'"
+	renamePairsError          = "Для '-mv' пути указываются парами: старый и новый префикс"
+	mergeFlagsError           = "Флаг '-merge' несовместим с '-rm', '-mv' и '-u'"
+	mergeSourcesError         = "Не указаны объединяемые архивы"
+	dupMergeError             = "Флаг '-dup' применяется только с '-merge'"
+	dupError                  = "Правило '-dup' должно быть first, last или error"
+	transcodeFlagsError       = "Флаг '-transcode' несовместим с '-merge', '-rm', '-mv' и '-u'"
+	transcodeParamsError      = "Флаги '-dict', '-lzworder' и '-lzwlit' не применяются с '-transcode', используются параметры архива"
+	transcodeSourceError      = "Для '-transcode' указывается один исходный архив"
 	archivePathInputPathError = "Имя архива и список файлов не указаны"
 	archivePathError          = "Имя архива не указано"
 	containsError             = "Путь к файлу не должен указывать на указаннный архив"